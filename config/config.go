@@ -0,0 +1,306 @@
+// Package config gathers the server settings. They are read once at startup
+// from flags and environment variables and checked before anything starts.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"mime"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aminofabian/notes/store"
+)
+
+// Config holds every setting of the server
+type Config struct {
+	// Addr is the address to listen on, as host:port
+	Addr string
+	// DBPath is the SQLite database file; empty keeps notes in memory
+	DBPath string
+	// TLSCert and TLSKey serve HTTPS when both are set
+	TLSCert string
+	TLSKey  string
+
+	JWTSecret []byte
+	// CORSAllowedOrigins lists the origins allowed to call the API; empty
+	// allows every origin
+	CORSAllowedOrigins []string
+
+	// MaxBodyBytes caps the size of JSON request bodies
+	MaxBodyBytes int64
+	// StoreTimeout bounds the store calls made while handling one request
+	StoreTimeout time.Duration
+	// IdempotencyTTL is how long an Idempotency-Key is remembered after its
+	// first use
+	IdempotencyTTL time.Duration
+	// FolderDeleteMode decides what deleting a folder does with its contents
+	FolderDeleteMode store.FolderDeleteMode
+	// DedupeNotes rejects new notes repeating an existing one's title and
+	// content
+	DedupeNotes bool
+
+	RateLimitRPS   float64
+	RateLimitBurst int
+	TrustProxy     bool
+
+	// AttachmentDir is the directory uploaded files are kept in, one
+	// subdirectory per note
+	AttachmentDir string
+	// MaxAttachmentBytes caps the size of one uploaded file
+	MaxAttachmentBytes int64
+	// AttachmentTypes lists the content types that may be uploaded. The
+	// type of a file is detected from its contents, not taken from the
+	// client.
+	AttachmentTypes []string
+
+	// WebhookURLs receive every note change, signed with WebhookSecret
+	WebhookURLs   []string
+	WebhookSecret string
+
+	// ReminderInterval is how often due reminders are looked for
+	ReminderInterval time.Duration
+}
+
+// Default returns the settings used for everything left unconfigured
+func Default() Config {
+	return Config{
+		Addr:   ":8080",
+		DBPath: "notes.db",
+
+		MaxBodyBytes:     1 << 20,
+		StoreTimeout:     5 * time.Second,
+		IdempotencyTTL:   24 * time.Hour,
+		FolderDeleteMode: store.FolderDeleteReject,
+
+		RateLimitRPS:   10,
+		RateLimitBurst: 20,
+
+		AttachmentDir:      "attachments",
+		MaxAttachmentBytes: 10 << 20,
+		AttachmentTypes:    []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"},
+
+		ReminderInterval: 30 * time.Second,
+	}
+}
+
+// Load reads the configuration from the environment and then from the
+// command-line flags in args, which take precedence. It reports every
+// malformed or invalid setting at once.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+	env := &envReader{}
+
+	if port := os.Getenv("PORT"); port != "" {
+		cfg.Addr = ":" + port
+	}
+	cfg.DBPath = env.string("DB_PATH", cfg.DBPath)
+	cfg.TLSCert = env.string("TLS_CERT", cfg.TLSCert)
+	cfg.TLSKey = env.string("TLS_KEY", cfg.TLSKey)
+
+	cfg.JWTSecret = []byte(os.Getenv("JWT_SECRET"))
+	cfg.CORSAllowedOrigins = env.list("CORS_ALLOWED_ORIGINS", cfg.CORSAllowedOrigins)
+
+	cfg.MaxBodyBytes = env.int64("MAX_BODY_BYTES", cfg.MaxBodyBytes)
+	cfg.StoreTimeout = env.duration("STORE_TIMEOUT", cfg.StoreTimeout)
+	cfg.IdempotencyTTL = env.duration("IDEMPOTENCY_TTL", cfg.IdempotencyTTL)
+	cfg.FolderDeleteMode = store.FolderDeleteMode(env.string("FOLDER_DELETE_MODE", string(cfg.FolderDeleteMode)))
+	cfg.DedupeNotes = env.bool("DEDUPE_NOTES", cfg.DedupeNotes)
+
+	cfg.RateLimitRPS = env.float("RATE_LIMIT_RPS", cfg.RateLimitRPS)
+	cfg.RateLimitBurst = int(env.int64("RATE_LIMIT_BURST", int64(cfg.RateLimitBurst)))
+	cfg.TrustProxy = env.bool("TRUST_PROXY", cfg.TrustProxy)
+
+	cfg.AttachmentDir = env.string("ATTACHMENT_DIR", cfg.AttachmentDir)
+	cfg.MaxAttachmentBytes = env.int64("MAX_ATTACHMENT_BYTES", cfg.MaxAttachmentBytes)
+	cfg.AttachmentTypes = env.list("ATTACHMENT_TYPES", cfg.AttachmentTypes)
+
+	cfg.WebhookURLs = env.list("WEBHOOK_URLS", cfg.WebhookURLs)
+	cfg.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+
+	cfg.ReminderInterval = env.duration("REMINDER_INTERVAL", cfg.ReminderInterval)
+
+	flags := flag.NewFlagSet("notes", flag.ExitOnError)
+	flags.StringVar(&cfg.Addr, "addr", cfg.Addr, "address to listen on (defaults to $PORT or :8080)")
+	flags.StringVar(&cfg.DBPath, "db", cfg.DBPath, "path to the SQLite database (empty for in-memory)")
+	flags.StringVar(&cfg.TLSCert, "tls-cert", cfg.TLSCert, "TLS certificate file; serves HTTPS together with -tls-key")
+	flags.StringVar(&cfg.TLSKey, "tls-key", cfg.TLSKey, "TLS private key file; serves HTTPS together with -tls-cert")
+	flags.Parse(args)
+
+	return cfg, errors.Join(append(env.errs, cfg.Validate())...)
+}
+
+// Validate checks the settings against each other and their allowed ranges
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, port, err := net.SplitHostPort(c.Addr); err != nil {
+		fail("invalid address %q: %v", c.Addr, err)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		fail("invalid address %q: port must be a number from 0 to 65535", c.Addr)
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		fail("-tls-cert and -tls-key must be set together")
+	}
+
+	if len(c.JWTSecret) == 0 {
+		fail("JWT_SECRET must be set")
+	}
+	for _, origin := range c.CORSAllowedOrigins {
+		if u, err := url.Parse(origin); err != nil || !isWebURL(origin) || u.Path != "" || u.RawQuery != "" {
+			fail("invalid CORS_ALLOWED_ORIGINS entry %q, want an origin such as https://example.com", origin)
+		}
+	}
+
+	if c.MaxBodyBytes <= 0 {
+		fail("MAX_BODY_BYTES must be positive")
+	}
+	if c.StoreTimeout <= 0 {
+		fail("STORE_TIMEOUT must be positive")
+	}
+	if c.IdempotencyTTL <= 0 {
+		fail("IDEMPOTENCY_TTL must be positive")
+	}
+	switch c.FolderDeleteMode {
+	case store.FolderDeleteReject, store.FolderDeleteMove:
+	default:
+		fail("invalid FOLDER_DELETE_MODE %q, want %q or %q", c.FolderDeleteMode, store.FolderDeleteReject, store.FolderDeleteMove)
+	}
+
+	if c.RateLimitRPS <= 0 {
+		fail("RATE_LIMIT_RPS must be positive")
+	}
+	if c.RateLimitBurst <= 0 {
+		fail("RATE_LIMIT_BURST must be positive")
+	}
+
+	if c.MaxAttachmentBytes <= 0 {
+		fail("MAX_ATTACHMENT_BYTES must be positive")
+	}
+	if len(c.AttachmentTypes) == 0 {
+		fail("ATTACHMENT_TYPES must list at least one content type")
+	}
+	for _, t := range c.AttachmentTypes {
+		if mediaType, params, err := mime.ParseMediaType(t); err != nil || mediaType != t || len(params) > 0 {
+			fail("invalid ATTACHMENT_TYPES entry %q, want a content type such as image/png", t)
+		}
+	}
+
+	for _, u := range c.WebhookURLs {
+		if !isWebURL(u) {
+			fail("invalid WEBHOOK_URLS entry %q, want an http or https URL", u)
+		}
+	}
+	if len(c.WebhookURLs) > 0 && c.WebhookSecret == "" {
+		fail("WEBHOOK_SECRET must be set when WEBHOOK_URLS is")
+	}
+
+	if c.ReminderInterval <= 0 {
+		fail("REMINDER_INTERVAL must be positive")
+	}
+	return errors.Join(errs...)
+}
+
+// UseTLS reports whether the server is to serve HTTPS
+func (c Config) UseTLS() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
+// isWebURL reports whether s is an absolute http or https URL
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// envReader reads typed environment variables, collecting the malformed ones
+// instead of stopping at the first
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) invalid(key, value, want string) {
+	e.errs = append(e.errs, fmt.Errorf("invalid %s %q, want %s", key, value, want))
+}
+
+// string returns the variable, even when set to "", or fallback if unset
+func (e *envReader) string(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
+
+func (e *envReader) int64(key string, fallback int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		e.invalid(key, v, "an integer")
+		return fallback
+	}
+	return n
+}
+
+func (e *envReader) float(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		e.invalid(key, v, "a number")
+		return fallback
+	}
+	return f
+}
+
+func (e *envReader) duration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.invalid(key, v, `a duration such as "5s"`)
+		return fallback
+	}
+	return d
+}
+
+func (e *envReader) bool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.invalid(key, v, "true or false")
+		return fallback
+	}
+	return b
+}
+
+// list splits a comma-separated variable, dropping blank entries
+func (e *envReader) list(key string, fallback []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envKeys are every variable Load reads
+var envKeys = []string{
+	"PORT", "DB_PATH", "TLS_CERT", "TLS_KEY", "JWT_SECRET", "CORS_ALLOWED_ORIGINS",
+	"MAX_BODY_BYTES", "STORE_TIMEOUT", "IDEMPOTENCY_TTL", "FOLDER_DELETE_MODE", "DEDUPE_NOTES",
+	"RATE_LIMIT_RPS", "RATE_LIMIT_BURST", "TRUST_PROXY",
+	"ATTACHMENT_DIR", "MAX_ATTACHMENT_BYTES", "ATTACHMENT_TYPES",
+	"WEBHOOK_URLS", "WEBHOOK_SECRET", "REMINDER_INTERVAL",
+}
+
+// setEnv unsets every variable Load reads, then sets those in env, for the
+// duration of the test
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, key := range envKeys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	setEnv(t, map[string]string{"JWT_SECRET": "secret"})
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Default()
+	want.JWTSecret = []byte("secret")
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadReadsEnvironment(t *testing.T) {
+	setEnv(t, map[string]string{
+		"JWT_SECRET":           "secret",
+		"PORT":                 "9000",
+		"DB_PATH":              "",
+		"CORS_ALLOWED_ORIGINS": "https://a.example, https://b.example:8443",
+		"MAX_BODY_BYTES":       "2048",
+		"STORE_TIMEOUT":        "2s",
+		"FOLDER_DELETE_MODE":   "move",
+		"DEDUPE_NOTES":         "true",
+		"ATTACHMENT_TYPES":     "image/png",
+		"WEBHOOK_URLS":         "https://hooks.example/notes",
+		"WEBHOOK_SECRET":       "hook-secret",
+	})
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case cfg.Addr != ":9000":
+		t.Errorf("Addr = %q, want :9000", cfg.Addr)
+	case cfg.DBPath != "":
+		t.Errorf("DBPath = %q, want it empty when DB_PATH is set to nothing", cfg.DBPath)
+	case !reflect.DeepEqual(cfg.CORSAllowedOrigins, []string{"https://a.example", "https://b.example:8443"}):
+		t.Errorf("CORSAllowedOrigins = %q", cfg.CORSAllowedOrigins)
+	case cfg.MaxBodyBytes != 2048:
+		t.Errorf("MaxBodyBytes = %d, want 2048", cfg.MaxBodyBytes)
+	case cfg.StoreTimeout != 2*time.Second:
+		t.Errorf("StoreTimeout = %s, want 2s", cfg.StoreTimeout)
+	case cfg.FolderDeleteMode != "move":
+		t.Errorf("FolderDeleteMode = %q, want move", cfg.FolderDeleteMode)
+	case !cfg.DedupeNotes:
+		t.Error("DedupeNotes = false, want true")
+	case !reflect.DeepEqual(cfg.AttachmentTypes, []string{"image/png"}):
+		t.Errorf("AttachmentTypes = %q", cfg.AttachmentTypes)
+	}
+}
+
+func TestLoadFlagsOverrideEnvironment(t *testing.T) {
+	setEnv(t, map[string]string{"JWT_SECRET": "secret", "PORT": "9000", "DB_PATH": "env.db"})
+
+	cfg, err := Load([]string{"-addr", "127.0.0.1:7000", "-db", "flag.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != "127.0.0.1:7000" || cfg.DBPath != "flag.db" {
+		t.Errorf("Addr, DBPath = %q, %q, want the flag values", cfg.Addr, cfg.DBPath)
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{"missing secret", map[string]string{"JWT_SECRET": ""}, nil, "JWT_SECRET must be set"},
+		{"bad port", map[string]string{"PORT": "http"}, nil, `invalid address ":http"`},
+		{"port out of range", nil, []string{"-addr", ":70000"}, "port must be a number from 0 to 65535"},
+		{"half of tls", nil, []string{"-tls-cert", "cert.pem"}, "-tls-cert and -tls-key must be set together"},
+		{"wildcard origin", map[string]string{"CORS_ALLOWED_ORIGINS": "*"}, nil, `invalid CORS_ALLOWED_ORIGINS entry "*"`},
+		{"origin with path", map[string]string{"CORS_ALLOWED_ORIGINS": "https://a.example/app"}, nil, "invalid CORS_ALLOWED_ORIGINS entry"},
+		{"malformed integer", map[string]string{"MAX_BODY_BYTES": "1MB"}, nil, `invalid MAX_BODY_BYTES "1MB", want an integer`},
+		{"zero body limit", map[string]string{"MAX_BODY_BYTES": "0"}, nil, "MAX_BODY_BYTES must be positive"},
+		{"malformed duration", map[string]string{"STORE_TIMEOUT": "5"}, nil, `invalid STORE_TIMEOUT "5"`},
+		{"negative timeout", map[string]string{"STORE_TIMEOUT": "-1s"}, nil, "STORE_TIMEOUT must be positive"},
+		{"unknown folder mode", map[string]string{"FOLDER_DELETE_MODE": "cascade"}, nil, `invalid FOLDER_DELETE_MODE "cascade"`},
+		{"malformed bool", map[string]string{"DEDUPE_NOTES": "maybe"}, nil, `invalid DEDUPE_NOTES "maybe", want true or false`},
+		{"zero rate", map[string]string{"RATE_LIMIT_RPS": "0"}, nil, "RATE_LIMIT_RPS must be positive"},
+		{"attachment type with params", map[string]string{"ATTACHMENT_TYPES": "text/plain; charset=utf-8"}, nil, "invalid ATTACHMENT_TYPES entry"},
+		{"webhook without secret", map[string]string{"WEBHOOK_URLS": "https://hooks.example"}, nil, "WEBHOOK_SECRET must be set"},
+		{"webhook not http", map[string]string{"WEBHOOK_URLS": "ftp://hooks.example", "WEBHOOK_SECRET": "s"}, nil, `invalid WEBHOOK_URLS entry "ftp://hooks.example"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"JWT_SECRET": "secret"}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+			setEnv(t, env)
+
+			_, err := Load(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	setEnv(t, map[string]string{
+		"PORT":               "http",
+		"STORE_TIMEOUT":      "soon",
+		"FOLDER_DELETE_MODE": "cascade",
+	})
+
+	_, err := Load(nil)
+	if err == nil {
+		t.Fatal("Load() succeeded, want an error")
+	}
+	for _, want := range []string{"invalid address", "JWT_SECRET", "STORE_TIMEOUT", "FOLDER_DELETE_MODE"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
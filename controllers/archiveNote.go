@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+func (h *Handlers) ArchiveNote(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+func (h *Handlers) UnarchiveNote(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+// setArchived updates the archived flag of the note named in the path and
+// writes back the note
+func (h *Handlers) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	n, err := h.notes.SetArchived(ctx, ownerID(r), id, archived)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// multipartOverhead leaves room for the multipart headers and boundaries
+// around the uploaded file
+const multipartOverhead = 64 << 10
+
+// UploadAttachment stores the file sent in the "file" field of a
+// multipart/form-data request and attaches it to the note
+func (h *Handlers) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		WriteError(w, http.StatusUnsupportedMediaType, "content type must be multipart/form-data")
+		return
+	}
+
+	// Check the note before reading the upload. The upload itself is not
+	// bound by the store timeout, so each store call gets its own context.
+	owner := ownerID(r)
+	ctx, cancel := h.storeContext(r)
+	_, err = h.notes.Get(ctx, owner, id)
+	cancel()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxAttachmentBytes+multipartOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid multipart body")
+		return
+	}
+
+	var a models.Attachment
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			WriteError(w, http.StatusBadRequest, "file is required")
+			return
+		}
+		if err != nil {
+			h.writeUploadError(w, err)
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		a, ok = h.saveAttachment(w, id, part)
+		if !ok {
+			return
+		}
+		break
+	}
+
+	ctx, cancel = h.storeContext(r)
+	defer cancel()
+
+	created, err := h.notes.CreateAttachment(ctx, owner, a)
+	if err != nil {
+		os.Remove(h.attachmentPath(a))
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// saveAttachment writes an uploaded file to disk after checking its type
+// and size. On failure it writes the response and removes the file.
+func (h *Handlers) saveAttachment(w http.ResponseWriter, noteID int64, part *multipart.Part) (models.Attachment, bool) {
+	filename := part.FileName()
+	if filename == "" {
+		WriteError(w, http.StatusBadRequest, "file must have a filename")
+		return models.Attachment{}, false
+	}
+	if len(filename) > 255 {
+		WriteError(w, http.StatusBadRequest, "filename must be at most 255 bytes")
+		return models.Attachment{}, false
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		h.writeUploadError(w, err)
+		return models.Attachment{}, false
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !slices.Contains(h.cfg.AttachmentTypes, mediaType) {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("content type %s is not allowed", mediaType))
+		return models.Attachment{}, false
+	}
+
+	key, err := newStorageKey()
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return models.Attachment{}, false
+	}
+	a := models.Attachment{
+		NoteID:      noteID,
+		Filename:    filename,
+		ContentType: contentType,
+		CreatedAt:   time.Now().UTC(),
+		StorageKey:  key,
+	}
+
+	path := h.attachmentPath(a)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		log.Printf("creating attachment directory: %v", err)
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return models.Attachment{}, false
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		log.Printf("creating attachment file: %v", err)
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return models.Attachment{}, false
+	}
+
+	// Read one byte past the limit to tell a file of exactly the limit from
+	// a larger one
+	body := io.MultiReader(bytes.NewReader(head), part)
+	a.Size, err = io.Copy(f, io.LimitReader(body, h.cfg.MaxAttachmentBytes+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && a.Size > h.cfg.MaxAttachmentBytes {
+		err = &http.MaxBytesError{Limit: h.cfg.MaxAttachmentBytes}
+	}
+	if err != nil {
+		os.Remove(path)
+		h.writeUploadError(w, err)
+		return models.Attachment{}, false
+	}
+	return a, true
+}
+
+// writeUploadError maps an error met while reading an upload to a response
+func (h *Handlers) writeUploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		WriteError(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("file must be at most %d bytes", h.cfg.MaxAttachmentBytes))
+		return
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		log.Printf("writing attachment: %v", err)
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	WriteError(w, http.StatusBadRequest, "invalid multipart body")
+}
+
+func (h *Handlers) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	list, err := h.notes.Attachments(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetAttachment serves an uploaded file with the content type detected when
+// it was uploaded. Images are shown inline; other files are downloaded.
+func (h *Handlers) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+	aid, ok := pathID(r, "attachmentID")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid attachment id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	a, err := h.notes.Attachment(ctx, ownerID(r), id, aid)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	f, err := os.Open(h.attachmentPath(a))
+	if err != nil {
+		log.Printf("opening attachment %d: %v", a.ID, err)
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	defer f.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(a.ContentType, "image/") {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, a.Filename, a.CreatedAt, f)
+}
+
+// removeAttachments deletes the files of a note that no longer exists
+func (h *Handlers) removeAttachments(noteID int64) {
+	dir := filepath.Join(h.cfg.AttachmentDir, strconv.FormatInt(noteID, 10))
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("removing attachments of note %d: %v", noteID, err)
+	}
+}
+
+// attachmentPath returns where the file of a is kept
+func (h *Handlers) attachmentPath(a models.Attachment) string {
+	return filepath.Join(h.cfg.AttachmentDir, strconv.FormatInt(a.NoteID, 10), a.StorageKey)
+}
+
+// newStorageKey returns a random file name for an upload
+func newStorageKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/aminofabian/notes/store"
+)
+
+// BulkCreateNotes creates every note in a JSON array, or none of them if any
+// note fails validation
+func (h *Handlers) BulkCreateNotes(w http.ResponseWriter, r *http.Request) {
+	var inputs []models.Note
+	if !h.decodeJSON(w, r, &inputs) {
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	owner := ownerID(r)
+	folders, err := h.folderSet(ctx, owner)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	batch := make([]models.Note, 0, len(inputs))
+	for i, input := range inputs {
+		err := input.Validate()
+		if err == nil && input.FolderID != nil && !folders[*input.FolderID] {
+			err = store.ErrFolderNotFound
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error:  fmt.Sprintf("note %d: %v", i, err),
+				Status: http.StatusBadRequest,
+				Index:  &i,
+			})
+			return
+		}
+
+		n := models.NewNote(input.Title, input.Content)
+		n.OwnerID = owner
+		n.Tags = input.Tags
+		n.FolderID = input.FolderID
+		n.RemindAt = input.RemindAt
+		batch = append(batch, n)
+	}
+
+	created, err := h.notes.CreateMany(ctx, batch)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aminofabian/notes/models"
+)
+
+func (h *Handlers) CreateFolder(w http.ResponseWriter, r *http.Request) {
+	var input models.Folder
+	if !h.decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f := models.NewFolder(input.Name, input.ParentID)
+	f.OwnerID = ownerID(r)
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	f, err := h.notes.CreateFolder(ctx, f)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, f)
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+// DeleteFolder removes a folder. Depending on the folder delete mode, a
+// folder that still has notes or subfolders is either refused with 409 or
+// emptied into the root first.
+func (h *Handlers) DeleteFolder(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid folder id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if err := h.notes.DeleteFolder(ctx, ownerID(r), id, h.cfg.FolderDeleteMode); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
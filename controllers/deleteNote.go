@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// maxBatchDelete bounds the number of ids DeleteNotes accepts at once
+const maxBatchDelete = 1000
+
+func (h *Handlers) DeleteNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if err := h.notes.Delete(ctx, ownerID(r), id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// batchDeleteResult reports which notes DeleteNotes deleted
+type batchDeleteResult struct {
+	Deleted  []int64 `json:"deleted"`
+	NotFound []int64 `json:"not_found"`
+}
+
+// DeleteNotes soft-deletes every note whose id is in a JSON array. Ids that
+// match no note are reported rather than failing the request.
+func (h *Handlers) DeleteNotes(w http.ResponseWriter, r *http.Request) {
+	var ids []int64
+	if !h.decodeJSON(w, r, &ids) {
+		return
+	}
+	if len(ids) > maxBatchDelete {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("at most %d ids can be deleted at once", maxBatchDelete))
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	deleted, notFound, err := h.notes.DeleteMany(ctx, ownerID(r), ids)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, batchDeleteResult{Deleted: deleted, NotFound: notFound})
+}
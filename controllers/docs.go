@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aminofabian/notes/docs"
+)
+
+// OpenAPISpec serves the OpenAPI document describing the API
+func OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(docs.Spec)
+}
+
+// APIDocs serves a Swagger UI page for the OpenAPI document
+func APIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docs.UI)
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aminofabian/notes/store"
+)
+
+// errorResponse is the body of every error returned by the API
+type errorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+
+	// Index points at the offending item of a batch request
+	Index *int `json:"index,omitempty"`
+
+	// ExistingID names the note a rejected duplicate would have repeated
+	ExistingID *int64 `json:"existing_id,omitempty"`
+}
+
+// WriteError writes a JSON error body with the given status code
+func WriteError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg, Status: status})
+}
+
+// writeStoreError maps an error returned by the store to a response
+func writeStoreError(w http.ResponseWriter, err error) {
+	var dup *store.DuplicateError
+	switch {
+	case errors.As(err, &dup):
+		writeJSON(w, http.StatusConflict, errorResponse{
+			Error:      err.Error(),
+			Status:     http.StatusConflict,
+			ExistingID: &dup.ID,
+		})
+		return
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, store.ErrVersionNotFound),
+		errors.Is(err, store.ErrFolderNotFound), errors.Is(err, store.ErrShareNotFound),
+		errors.Is(err, store.ErrAttachmentNotFound):
+		WriteError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, store.ErrParentNotFound), errors.Is(err, store.ErrFolderCycle):
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, store.ErrFolderNotEmpty), errors.Is(err, store.ErrNoReminder):
+		WriteError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		WriteError(w, http.StatusGatewayTimeout, "store timed out")
+		return
+	case errors.Is(err, context.Canceled):
+		WriteError(w, http.StatusServiceUnavailable, "request canceled")
+		return
+	}
+	WriteError(w, http.StatusInternalServerError, "internal server error")
+}
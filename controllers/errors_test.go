@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/aminofabian/notes/store"
+	"github.com/gorilla/mux"
+)
+
+// slowStore never answers a lookup until its context is done. Methods it
+// does not override are left nil and panic if called.
+type slowStore struct {
+	store.Store
+}
+
+func (slowStore) Get(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	<-ctx.Done()
+	return models.Note{}, ctx.Err()
+}
+
+func (slowStore) List(ctx context.Context, opts store.ListOptions) ([]models.Note, int, error) {
+	<-ctx.Done()
+	return nil, 0, ctx.Err()
+}
+
+func TestStoreTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.StoreTimeout = 20 * time.Millisecond
+	h := newTestHandlers(cfg, slowStore{})
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		req     *http.Request
+	}{
+		{"get", h.GetNote, mux.SetURLVars(httptest.NewRequest("GET", "/notes/1", nil), map[string]string{"id": "1"})},
+		{"list", h.ListNotes, httptest.NewRequest("GET", "/notes", nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			rec := serve(t, tt.handler, tt.req, "alice")
+
+			if rec.Code != http.StatusGatewayTimeout {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, http.StatusGatewayTimeout, rec.Body)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("request took %s despite a %s store timeout", elapsed, cfg.StoreTimeout)
+			}
+		})
+	}
+}
+
+func TestStoreCallsEndWithClient(t *testing.T) {
+	h := newTestHandlers(testConfig(), slowStore{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/notes/1", nil).WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+	// The client goes away while the store is still working
+	time.AfterFunc(20*time.Millisecond, cancel)
+	rec := serve(t, h.GetNote, req, "alice")
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d (%s)", rec.Code, http.StatusServiceUnavailable, rec.Body)
+	}
+}
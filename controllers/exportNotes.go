@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aminofabian/notes/store"
+)
+
+// ExportNotes downloads all of the caller's notes, oldest first, as a single
+// Markdown document or as a JSON array that POST /notes/import accepts
+func (h *Handlers) ExportNotes(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "markdown"
+	}
+	if format != "markdown" && format != "json" {
+		WriteError(w, http.StatusBadRequest, "format must be markdown or json")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	list, _, err := h.notes.List(ctx, store.ListOptions{
+		OwnerID: ownerID(r),
+		Sort:    store.Sort{Field: store.SortCreatedAt},
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="notes.json"`)
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+
+	var b strings.Builder
+	for i, n := range list {
+		if i > 0 {
+			b.WriteString("\n---\n\n")
+		}
+		fmt.Fprintf(&b, "# %s\n", headingText(n.Title))
+		if content := strings.TrimSpace(n.Content); content != "" {
+			b.WriteString("\n" + content + "\n")
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="notes.md"`)
+	w.Write([]byte(b.String()))
+}
+
+// headingText joins the lines of a title with spaces, since a heading ends
+// at the first line break and the rest would read as content, or even as a
+// separator between notes
+func headingText(title string) string {
+	lines := strings.FieldsFunc(title, func(r rune) bool { return r == '\r' || r == '\n' })
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/aminofabian/notes/store"
+)
+
+func TestExportMarkdownTitleLineBreaks(t *testing.T) {
+	st := store.NewMemoryStore()
+	for _, title := range []string{"first\n---\n# injected", "second\r\nline"} {
+		n := models.NewNote(title, "body")
+		n.OwnerID = "alice"
+		if err := n.Validate(); err != nil {
+			t.Fatalf("%q: %v", title, err)
+		}
+		if _, err := st.Create(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := newTestHandlers(testConfig(), st)
+
+	rec := serve(t, h.ExportNotes, httptest.NewRequest("GET", "/notes/export", nil), "alice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	want := "# first --- # injected\n\nbody\n\n---\n\n# second line\n\nbody\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("export =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+func (h *Handlers) GetFolder(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid folder id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	f, err := h.notes.GetFolder(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, f)
+}
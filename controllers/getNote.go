@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aminofabian/notes/models"
+)
+
+func (h *Handlers) GetNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	n, err := h.notes.Get(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	// Let polling clients skip the body when the note has not changed
+	etag := noteETag(n)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
+
+// noteETag derives an entity tag from the JSON form of n, so it changes
+// whenever any field of the note does
+func noteETag(n models.Note) string {
+	b, _ := json.Marshal(n)
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag,
+// using the weak comparison the header calls for
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -2,15 +2,64 @@ package controllers
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/aminofabian/notes/store"
 )
 
-func GetNotes(res http.ResponseWriter, req *http.Request) {
-	// Get all notes from the database
+// maxIdempotencyKeyLength bounds the Idempotency-Key header
+const maxIdempotencyKeyLength = 255
+
+func (h *Handlers) GetNotes(w http.ResponseWriter, r *http.Request) {
+	// Decode the note from the request body
+	var input models.Note
+	if !h.decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Save the note
+	n := models.NewNote(input.Title, input.Content)
+	n.OwnerID = ownerID(r)
+	n.Tags = input.Tags
+	n.FolderID = input.FolderID
+	n.RemindAt = input.RemindAt
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if !h.checkFolder(ctx, w, r, n.FolderID) {
+		return
+	}
 
-	//manipulate the notes
+	// Catch notes pasted twice by mistake, when asked to
+	opts := store.CreateOptions{Dedupe: h.cfg.DedupeNotes || r.URL.Query().Get("dedupe") == "true"}
 
-	//save the notes to the database
+	// A retried request with the same Idempotency-Key gets the note created
+	// by the first attempt instead of a duplicate, or a 409 for one
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			WriteError(w, http.StatusBadRequest, "idempotency key too long")
+			return
+		}
+		opts.Key = key
+		opts.ExpiresAt = time.Now().Add(h.cfg.IdempotencyTTL)
+	}
 
-	//return the notes to the client
+	n, created, err := h.notes.CreateWith(ctx, n, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if !created {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
 
+	// Return the note to the client
+	writeJSON(w, http.StatusCreated, n)
 }
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthTTL is how long a store check is reused, so frequent probes do not
+// all reach the database
+const healthTTL = 5 * time.Second
+
+// healthCheck caches the result of the last store check
+type healthCheck struct {
+	sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func (h *Handlers) Health(w http.ResponseWriter, r *http.Request) {
+	h.health.Lock()
+	if time.Since(h.health.checked) > healthTTL {
+		ctx, cancel := h.storeContext(r)
+		h.health.err = h.notes.Ping(ctx)
+		cancel()
+		h.health.checked = time.Now()
+	}
+	err := h.health.err
+	h.health.Unlock()
+
+	if err != nil {
+		WriteError(w, http.StatusServiceUnavailable, "store unavailable")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// ImportNotes recreates notes from the JSON produced by GET
+// /notes/export?format=json. Imported notes belong to the caller.
+func (h *Handlers) ImportNotes(w http.ResponseWriter, r *http.Request) {
+	var inputs []models.Note
+	if !h.decodeJSON(w, r, &inputs) {
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	// Folders are not exported, so only keep references to folders the
+	// caller still has
+	owner := ownerID(r)
+	folders, err := h.folderSet(ctx, owner)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range inputs {
+		n := &inputs[i]
+		if err := n.Validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{
+				Error:  fmt.Sprintf("note %d: %v", i, err),
+				Status: http.StatusBadRequest,
+				Index:  &i,
+			})
+			return
+		}
+
+		n.OwnerID = owner
+		n.DeletedAt = nil
+		if n.FolderID != nil && !folders[*n.FolderID] {
+			n.FolderID = nil
+		}
+		if n.CreatedAt.IsZero() {
+			n.CreatedAt = now
+		}
+		if n.UpdatedAt.IsZero() {
+			n.UpdatedAt = n.CreatedAt
+		}
+	}
+
+	preserveIDs := r.URL.Query().Get("preserve_ids") == "true"
+
+	imported, skipped, err := h.notes.Import(ctx, inputs, preserveIDs)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{"imported": len(imported), "skipped": skipped})
+}
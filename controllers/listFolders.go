@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+// ListFolders returns every folder of the caller as a flat list; parent_id
+// links them into a tree
+func (h *Handlers) ListFolders(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	list, err := h.notes.ListFolders(ctx, ownerID(r))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
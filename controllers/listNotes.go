@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/aminofabian/notes/store"
+)
+
+// Page sizes and order for GET /notes
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	defaultSort  = "-created_at"
+)
+
+func (h *Handlers) ListNotes(w http.ResponseWriter, r *http.Request) {
+	sortParam := r.URL.Query().Get("sort")
+	if sortParam == "" {
+		sortParam = defaultSort
+	}
+	sortBy, err := store.ParseSort(sortParam)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sortBy.PinnedFirst = true
+
+	opts := store.ListOptions{
+		OwnerID: ownerID(r),
+		Limit:   queryInt(r, "limit", defaultLimit),
+		Offset:  queryInt(r, "offset", 0),
+		Sort:    sortBy,
+		Tags:    r.URL.Query()["tag"],
+		Query:   r.URL.Query().Get("q"),
+
+		IncludeDeleted: r.URL.Query().Get("include_deleted") == "true",
+		Archived:       store.ArchivedExclude,
+	}
+	if r.URL.Query().Get("archived") == "true" {
+		opts.Archived = store.ArchivedOnly
+	}
+	if folder := r.URL.Query().Get("folder"); folder != "" {
+		id, ok := parseID(folder)
+		if !ok {
+			WriteError(w, http.StatusBadRequest, "invalid folder id")
+			return
+		}
+		opts.FolderID = &id
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultLimit
+	}
+	if opts.Limit > maxLimit {
+		opts.Limit = maxLimit
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	list, total, err := h.notes.List(ctx, opts)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("X-Limit", strconv.Itoa(opts.Limit))
+	w.Header().Set("X-Offset", strconv.Itoa(opts.Offset))
+	writeJSON(w, http.StatusOK, list)
+}
+
+// queryInt reads a non-negative integer query parameter, returning fallback
+// when it is missing or invalid
+func queryInt(r *http.Request, key string, fallback int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil || v < 0 {
+		return fallback
+	}
+	return v
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"context"
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics returns a collector reporting the size of the store. It is read
+// from the store on every scrape and is NaN when the store cannot be reached.
+func (h *Handlers) Metrics() prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "notes_stored",
+		Help: "Number of notes in the store, not counting deleted ones.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), h.cfg.StoreTimeout)
+		defer cancel()
+
+		count, err := h.notes.Count(ctx)
+		if err != nil {
+			return math.NaN()
+		}
+		return float64(count)
+	})
+}
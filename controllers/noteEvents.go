@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsConnections tracks the WebSocket connections still being served. Once
+// closing is set no new ones are accepted.
+type wsConnections struct {
+	sync.Mutex
+	closing bool
+	wg      sync.WaitGroup
+}
+
+// CloseEvents ends every WebSocket connection and waits for their handlers
+// to return, or for ctx to be done. http.Server.Shutdown does not wait for
+// them itself.
+func (h *Handlers) CloseEvents(ctx context.Context) error {
+	h.ws.Lock()
+	h.ws.closing = true
+	h.ws.Unlock()
+
+	h.events.Close()
+
+	done := make(chan struct{})
+	go func() {
+		h.ws.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Timing of the WebSocket connection. Pings are sent often enough that a
+// client has time to answer before pongWait runs out.
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+)
+
+// Tokens are passed explicitly rather than in cookies, so a page on another
+// origin cannot open a connection on behalf of a user and every origin can
+// be accepted
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// NoteEvents streams the changes to the caller's notes as JSON events over a
+// WebSocket until the client disconnects or the server shuts down
+func (h *Handlers) NoteEvents(w http.ResponseWriter, r *http.Request) {
+	h.ws.Lock()
+	if h.ws.closing {
+		h.ws.Unlock()
+		WriteError(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+	h.ws.wg.Add(1)
+	h.ws.Unlock()
+	defer h.ws.wg.Done()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := h.events.Subscribe(ownerID(r))
+	defer unsubscribe()
+
+	// Clients only talk to us to answer pings or to close the connection.
+	// Reading stops once the connection fails, which closing it on return
+	// guarantees.
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				// Dropped for falling behind, or shutting down
+				msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
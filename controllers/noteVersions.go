@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handlers) ListNoteVersions(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	versions, err := h.notes.Versions(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, versions)
+}
+
+// RevertNote makes a previous version the current content of a note. The
+// state being replaced is itself kept as a new version.
+func (h *Handlers) RevertNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	version, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil || version <= 0 {
+		WriteError(w, http.StatusBadRequest, "invalid version")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	owner := ownerID(r)
+	v, err := h.notes.Version(ctx, owner, id, version)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	n, err := h.notes.Get(ctx, owner, id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	n.Title = v.Title
+	n.Content = v.Content
+	n.Tags = v.Tags
+
+	n, err = h.notes.Update(ctx, n)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
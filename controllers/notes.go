@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/aminofabian/notes/config"
+	"github.com/aminofabian/notes/middleware"
+	"github.com/aminofabian/notes/store"
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the API. Its methods are the handlers of every route that
+// touches the store, all sharing one store and configuration.
+type Handlers struct {
+	cfg    config.Config
+	notes  store.Store
+	events *store.Hub
+
+	health healthCheck
+	ws     wsConnections
+}
+
+// New returns the handlers for the notes in st, configured by cfg. Every
+// change made through them is published to events.
+func New(cfg config.Config, st store.Store, events *store.Hub) *Handlers {
+	return &Handlers{
+		cfg:    cfg,
+		notes:  store.WithEvents(st, events),
+		events: events,
+	}
+}
+
+// storeContext returns the context for store calls. It is cancelled when the
+// client goes away or the configured store timeout has passed.
+func (h *Handlers) storeContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), h.cfg.StoreTimeout)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeJSON decodes the JSON request body into v. On failure it writes the
+// error response and returns false.
+func (h *Handlers) decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		WriteError(w, http.StatusUnsupportedMediaType, "content type must be application/json")
+		return false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return false
+		}
+		WriteError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+	return true
+}
+
+// ownerID returns the authenticated user making the request
+func ownerID(r *http.Request) string {
+	id, _ := middleware.UserID(r.Context())
+	return id
+}
+
+// pathID parses the id in the named path variable, such as {id}
+func pathID(r *http.Request, name string) (int64, bool) {
+	return parseID(mux.Vars(r)[name])
+}
+
+func parseID(s string) (int64, bool) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// checkFolder makes sure a note is being put in one of the caller's own
+// folders. Otherwise it writes the error response and returns false.
+func (h *Handlers) checkFolder(ctx context.Context, w http.ResponseWriter, r *http.Request, id *int64) bool {
+	if id == nil {
+		return true
+	}
+
+	_, err := h.notes.GetFolder(ctx, ownerID(r), *id)
+	if errors.Is(err, store.ErrFolderNotFound) {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return false
+	} else if err != nil {
+		writeStoreError(w, err)
+		return false
+	}
+	return true
+}
+
+// folderSet returns the ids of every folder of an owner
+func (h *Handlers) folderSet(ctx context.Context, owner string) (map[int64]bool, error) {
+	folders, err := h.notes.ListFolders(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[int64]bool, len(folders))
+	for _, f := range folders {
+		set[f.ID] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofabian/notes/config"
+	"github.com/aminofabian/notes/middleware"
+	"github.com/aminofabian/notes/store"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+// testConfig returns the default configuration with a JWT secret set
+func testConfig() config.Config {
+	cfg := config.Default()
+	cfg.JWTSecret = []byte(testSecret)
+	return cfg
+}
+
+// newTestHandlers returns handlers backed by st, or by a new in-memory store
+// when st is nil
+func newTestHandlers(cfg config.Config, st store.Store) *Handlers {
+	if st == nil {
+		st = store.NewMemoryStore()
+	}
+	return New(cfg, st, store.NewHub())
+}
+
+// serve runs handler for req as user, behind the same authentication the
+// routes use
+func serve(t *testing.T, handler http.HandlerFunc, req *http.Request, user string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: user}).
+		SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	rec := httptest.NewRecorder()
+	middleware.RequireAuth([]byte(testSecret))(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+// postNote sends body to the create endpoint as JSON
+func postNote(t *testing.T, h *Handlers, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/notes", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return serve(t, h.GetNotes, req, "alice")
+}
+
+// noteOfSize returns a note whose JSON encoding is exactly size bytes
+func noteOfSize(size int) string {
+	const frame = `{"title":"t","content":""}`
+	return `{"title":"t","content":"` + strings.Repeat("a", size-len(frame)) + `"}`
+}
+
+func TestDecodeJSONRejectsOversizedBody(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxBodyBytes = 256
+	h := newTestHandlers(cfg, nil)
+
+	if rec := postNote(t, h, noteOfSize(256)); rec.Code != http.StatusCreated {
+		t.Errorf("body at the limit: status = %d, want %d (%s)", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	rec := postNote(t, h, noteOfSize(257))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("body over the limit: status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if !strings.Contains(rec.Body.String(), `"status":413`) {
+		t.Errorf("body over the limit: got %s, want a JSON error", rec.Body)
+	}
+}
+
+func TestDecodeJSONDefaultLimit(t *testing.T) {
+	h := newTestHandlers(testConfig(), nil)
+
+	rec := postNote(t, h, noteOfSize(1<<20+1))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// TestMaxBodyBytesSetting checks that MAX_BODY_BYTES reaches the handlers
+func TestMaxBodyBytesSetting(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("MAX_BODY_BYTES", "100")
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandlers(cfg, nil)
+
+	if rec := postNote(t, h, noteOfSize(100)); rec.Code != http.StatusCreated {
+		t.Errorf("100 bytes: status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := postNote(t, h, noteOfSize(101)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("101 bytes: status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestDecodeJSONRequiresJSON(t *testing.T) {
+	h := newTestHandlers(testConfig(), nil)
+
+	req := httptest.NewRequest("POST", "/notes", strings.NewReader(`{"title":"t"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	if rec := serve(t, h.GetNotes, req, "alice"); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+// TestIdempotentRetryWithDedupe checks that a retried request is replayed
+// rather than refused as a duplicate of the note it created
+func TestIdempotentRetryWithDedupe(t *testing.T) {
+	h := newTestHandlers(testConfig(), nil)
+
+	post := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/notes?dedupe=true", strings.NewReader(`{"title":"t","content":"c"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		return serve(t, h.GetNotes, req, "alice")
+	}
+
+	first := post("k1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first: status = %d, want %d (%s)", first.Code, http.StatusCreated, first.Body)
+	}
+
+	retry := post("k1")
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry: status = %d, replayed = %q, want a replayed %d", retry.Code, retry.Header().Get("Idempotent-Replayed"), http.StatusCreated)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("retry: body = %s, want %s", retry.Body, first.Body)
+	}
+
+	other := post("k2")
+	if other.Code != http.StatusConflict || !strings.Contains(other.Body.String(), `"existing_id":1`) {
+		t.Errorf("new key: status = %d (%s), want %d naming note 1", other.Code, other.Body, http.StatusConflict)
+	}
+}
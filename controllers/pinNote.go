@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+func (h *Handlers) PinNote(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+func (h *Handlers) UnpinNote(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+// setPinned updates the pinned flag of the note named in the path and
+// writes back the note
+func (h *Handlers) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	n, err := h.notes.SetPinned(ctx, ownerID(r), id, pinned)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
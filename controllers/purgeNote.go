@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+func (h *Handlers) PurgeNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if err := h.notes.Purge(ctx, ownerID(r), id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.removeAttachments(id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aminofabian/notes/store"
+)
+
+// ListReminders lists the caller's unacknowledged reminders due at or before
+// ?before=, an RFC 3339 time defaulting to now
+func (h *Handlers) ListReminders(w http.ResponseWriter, r *http.Request) {
+	before := time.Now()
+	if v := r.URL.Query().Get("before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, "before must be an RFC 3339 time")
+			return
+		}
+		before = t
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	due, err := h.notes.Reminders(ctx, store.ReminderOptions{OwnerID: ownerID(r), Before: before})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, due)
+}
+
+// AcknowledgeReminder takes the reminder of a note off the list of due
+// reminders and writes back the note
+func (h *Handlers) AcknowledgeReminder(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	n, err := h.notes.AcknowledgeReminder(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
+
+// RunReminders publishes a reminder event to the hub for every reminder that
+// falls due while it runs, until ctx is done. Reminders that were already
+// due when it started are not announced again.
+func (h *Handlers) RunReminders(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.ReminderInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// On failure the same reminders are looked for again next time
+		now := time.Now()
+		storeCtx, cancel := context.WithTimeout(ctx, h.cfg.StoreTimeout)
+		due, err := h.notes.Reminders(storeCtx, store.ReminderOptions{After: &last, Before: now})
+		cancel()
+		if err != nil {
+			log.Printf("checking reminders: %v", err)
+			continue
+		}
+
+		for _, n := range due {
+			h.events.Publish(store.Event{Type: store.EventReminder, NoteID: n.ID, Note: &n, OwnerID: n.OwnerID})
+		}
+		last = now
+	}
+}
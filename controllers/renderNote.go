@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdown converts note content to HTML, with GitHub's tables, task lists,
+// strikethrough and autolinks. Raw HTML in notes is left out.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// htmlPolicy strips anything that could run script from rendered notes. It
+// backs up goldmark in case a note gets something past it, such as a
+// javascript: link. Task list checkboxes are kept.
+var htmlPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}()
+
+// RenderNote returns the content of a note converted from Markdown to
+// sanitized HTML
+func (h *Handlers) RenderNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	n, err := h.notes.Get(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	// The HTML only depends on the note, so it shares the note's ETag
+	etag := noteETag(n)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(n.Content), &buf); err != nil {
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(htmlPolicy.SanitizeBytes(buf.Bytes()))
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/gorilla/mux"
+)
+
+// shareTokenBytes is how much randomness goes into a share token
+const shareTokenBytes = 32
+
+// shareRequest is the optional body of a share request
+type shareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ShareNote gives the note a new public read-only link, revoking any earlier
+// one. The token is only returned here; the store keeps a hash of it.
+func (h *Handlers) ShareNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	var req shareRequest
+	if r.ContentLength != 0 && !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		WriteError(w, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if err := h.notes.ShareNote(ctx, ownerID(r), id, hashShareToken(token), req.ExpiresAt); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.Share{
+		NoteID:    id,
+		Token:     token,
+		URL:       "/shared/" + token,
+		ExpiresAt: req.ExpiresAt,
+	})
+}
+
+// UnshareNote revokes the share link of the note
+func (h *Handlers) UnshareNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if err := h.notes.UnshareNote(ctx, ownerID(r), id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedNote returns the public view of a shared note. It needs no
+// authentication; the token is the credential.
+func (h *Handlers) GetSharedNote(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	n, err := h.notes.SharedNote(ctx, hashShareToken(mux.Vars(r)["token"]))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.NewSharedNote(n))
+}
+
+// newShareToken returns a random URL-safe token
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashShareToken returns the form of a token kept by the store, so that
+// reading the database does not give away working links
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
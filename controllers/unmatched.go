@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aminofabian/notes/middleware"
+	"github.com/gorilla/mux"
+)
+
+// Unmatched returns the handler for requests no route of router matches.
+// When the path is routed for other methods it replies 405 and lists them in
+// the Allow header, and 404 otherwise. The methods are looked up here rather
+// than left to router, which loses track of them inside subrouters.
+func Unmatched(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods := middleware.RouteMethods(router, r)
+		if len(methods) == 0 {
+			WriteError(w, http.StatusNotFound, "no such endpoint")
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		WriteError(w, http.StatusMethodNotAllowed, "method "+r.Method+" not allowed")
+	})
+}
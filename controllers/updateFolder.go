@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// UpdateFolder renames a folder and sets its parent; a missing parent_id
+// moves it to the root
+func (h *Handlers) UpdateFolder(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid folder id")
+		return
+	}
+
+	var input models.Folder
+	if !h.decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	f := models.Folder{
+		ID:       id,
+		OwnerID:  ownerID(r),
+		Name:     input.Name,
+		ParentID: input.ParentID,
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	f, err := h.notes.UpdateFolder(ctx, f)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, f)
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/aminofabian/notes/models"
+)
+
+func (h *Handlers) UpdateNote(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r, "id")
+	if !ok {
+		WriteError(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	var input models.Note
+	if !h.decodeJSON(w, r, &input) {
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := h.storeContext(r)
+	defer cancel()
+
+	if !h.checkFolder(ctx, w, r, input.FolderID) {
+		return
+	}
+
+	n, err := h.notes.Get(ctx, ownerID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	// Keep the id, owner and creation time, replace everything else
+	n.Title = input.Title
+	n.Content = input.Content
+	n.Tags = input.Tags
+	n.FolderID = input.FolderID
+	n.RemindAt = input.RemindAt
+
+	n, err = h.notes.Update(ctx, n)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, n)
+}
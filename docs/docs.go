@@ -0,0 +1,86 @@
+// Package docs holds the OpenAPI description of the API
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Spec is the OpenAPI 3.0 document describing every route. It is written by
+// hand, so update it together with the routes; Check points out where the
+// two disagree.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// UI is a Swagger UI page that loads the spec from /openapi.json
+//
+//go:embed swagger.html
+var UI []byte
+
+// Check compares the routes served by r with the operations in Spec and
+// describes every difference. OPTIONS requests are left out, since the
+// CORS middleware answers them.
+func Check(r *mux.Router) ([]string, error) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(Spec, &spec); err != nil {
+		return nil, err
+	}
+
+	documented := map[string]bool{}
+	for path, item := range spec.Paths {
+		for method := range item {
+			switch method {
+			case "get", "put", "post", "delete", "patch", "head":
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	served := map[string]bool{}
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		// Subrouters have no handler of their own
+		if route.GetHandler() == nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		// Routes taking any method are documented as GET
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{"GET"}
+		}
+		for _, method := range methods {
+			if method != "OPTIONS" {
+				served[method+" "+path] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for op := range served {
+		if !documented[op] {
+			problems = append(problems, op+" is missing from the OpenAPI spec")
+		}
+	}
+	for op := range documented {
+		if !served[op] {
+			problems = append(problems, op+" is in the OpenAPI spec but not served")
+		}
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
@@ -1,31 +1,326 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/aminofabian/notes/config"
 	"github.com/aminofabian/notes/controllers"
+	"github.com/aminofabian/notes/docs"
 	"github.com/aminofabian/notes/middleware"
+	"github.com/aminofabian/notes/store"
+	"github.com/aminofabian/notes/webhooks"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// shutdownTimeout bounds how long in-flight requests get to finish on exit
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+
+	// Open the note store
+	st, err := openStore(cfg)
+	if err != nil {
+		log.Fatalf("open database: %v", err)
+	}
+	defer st.Close()
+
+	// Publish note changes to WebSocket clients, and to webhooks if any are
+	// configured
+	events := store.NewHub()
+	api := controllers.New(cfg, st, events)
+	prometheus.MustRegister(api.Metrics())
+
+	var hooks *webhooks.Dispatcher
+	if len(cfg.WebhookURLs) > 0 {
+		hooks = webhooks.New(cfg.WebhookURLs, []byte(cfg.WebhookSecret))
+		events.Observe(hooks.Enqueue)
+		log.Printf("delivering webhooks to %d url(s)", len(cfg.WebhookURLs))
+	}
+
+	// Announce reminders as they fall due, to both of the above
+	remindCtx, stopReminders := context.WithCancel(context.Background())
+	remindersDone := make(chan struct{})
+	go func() {
+		api.RunReminders(remindCtx)
+		close(remindersDone)
+	}()
 
 	// Initialize router
 	r := mux.NewRouter()
 
-	// Apply CORS middleware to all routes
-	r.Use(middleware.EnableCORS)
+	// Apply middleware to all routes. Metrics wraps everything so requests
+	// that panic are still counted, and RequestID runs early so the id is
+	// in every response and log line. Recover comes next so it also
+	// catches panics raised by the other middleware.
+	middlewares := []mux.MiddlewareFunc{
+		middleware.Metrics,
+		middleware.RequestID,
+		middleware.Recover,
+		middleware.RequestLogger,
+		middleware.Gzip,
+		middleware.EnableCORS(r, cfg.CORSAllowedOrigins),
+		middleware.RateLimit(cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.TrustProxy, "/health", "/metrics"),
+	}
+	r.Use(middlewares...)
+
+	// Requests no route matches skip the router's middleware, so their
+	// handler is wrapped in it here to still be logged, counted and given
+	// CORS headers
+	unmatched := withMiddleware(controllers.Unmatched(r), middlewares)
+	r.NotFoundHandler = unmatched
+	r.MethodNotAllowedHandler = unmatched
 
 	// Routes
 	r.HandleFunc("/",
 		controllers.Hello,
 	)
 
-	r.HandleFunc("/notes",
-		controllers.GetNotes,
+	r.HandleFunc("/health",
+		api.Health,
+	).Methods("GET")
+
+	r.Handle("/metrics",
+		promhttp.Handler(),
+	).Methods("GET")
+
+	r.HandleFunc("/openapi.json",
+		controllers.OpenAPISpec,
+	).Methods("GET")
+
+	r.HandleFunc("/docs",
+		controllers.APIDocs,
+	).Methods("GET")
+
+	// Shared notes are public; the token in the path is the credential
+	r.HandleFunc("/shared/{token}",
+		api.GetSharedNote,
+	).Methods("GET")
+
+	// Note routes require a valid token
+	notes := r.PathPrefix("/notes").Subrouter()
+	notes.Use(middleware.RequireAuth(cfg.JWTSecret))
+
+	notes.HandleFunc("",
+		api.GetNotes,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("",
+		api.ListNotes,
+	).Methods("GET")
+
+	notes.HandleFunc("",
+		api.DeleteNotes,
+	).Methods("DELETE", "OPTIONS")
+
+	notes.HandleFunc("/bulk",
+		api.BulkCreateNotes,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/export",
+		api.ExportNotes,
+	).Methods("GET")
+
+	notes.HandleFunc("/import",
+		api.ImportNotes,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/reminders",
+		api.ListReminders,
+	).Methods("GET")
+
+	notes.HandleFunc("/ws",
+		api.NoteEvents,
+	).Methods("GET")
+
+	notes.HandleFunc("/{id}",
+		api.GetNote,
+	).Methods("GET")
+
+	notes.HandleFunc("/{id}",
+		api.UpdateNote,
+	).Methods("PUT", "OPTIONS")
+
+	notes.HandleFunc("/{id}",
+		api.DeleteNote,
+	).Methods("DELETE", "OPTIONS")
+
+	notes.HandleFunc("/{id}/attachments",
+		api.UploadAttachment,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/attachments",
+		api.ListAttachments,
+	).Methods("GET")
+
+	notes.HandleFunc("/{id}/attachments/{attachmentID}",
+		api.GetAttachment,
+	).Methods("GET")
+
+	notes.HandleFunc("/{id}/render",
+		api.RenderNote,
+	).Methods("GET")
+
+	notes.HandleFunc("/{id}/pin",
+		api.PinNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/unpin",
+		api.UnpinNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/archive",
+		api.ArchiveNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/unarchive",
+		api.UnarchiveNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/restore",
+		api.RestoreNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/purge",
+		api.PurgeNote,
+	).Methods("DELETE", "OPTIONS")
+
+	notes.HandleFunc("/{id}/versions",
+		api.ListNoteVersions,
+	).Methods("GET")
+
+	notes.HandleFunc("/{id}/revert/{version}",
+		api.RevertNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/reminder/ack",
+		api.AcknowledgeReminder,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/share",
+		api.ShareNote,
+	).Methods("POST", "OPTIONS")
+
+	notes.HandleFunc("/{id}/share",
+		api.UnshareNote,
+	).Methods("DELETE", "OPTIONS")
+
+	// Folder routes require a valid token too
+	folders := r.PathPrefix("/folders").Subrouter()
+	folders.Use(middleware.RequireAuth(cfg.JWTSecret))
+
+	folders.HandleFunc("",
+		api.CreateFolder,
 	).Methods("POST", "OPTIONS")
 
+	folders.HandleFunc("",
+		api.ListFolders,
+	).Methods("GET")
+
+	folders.HandleFunc("/{id}",
+		api.GetFolder,
+	).Methods("GET")
+
+	folders.HandleFunc("/{id}",
+		api.UpdateFolder,
+	).Methods("PUT", "OPTIONS")
+
+	folders.HandleFunc("/{id}",
+		api.DeleteFolder,
+	).Methods("DELETE", "OPTIONS")
+
+	// Flag routes the OpenAPI spec has not caught up with
+	problems, err := docs.Check(r)
+	if err != nil {
+		log.Fatalf("checking OpenAPI spec: %v", err)
+	}
+	for _, problem := range problems {
+		log.Printf("openapi: %s", problem)
+	}
+
 	// Start server
-	http.ListenAndServe(":8080", r)
+	srv := newServer(cfg, r)
+
+	go func() {
+		var err error
+		if cfg.UseTLS() {
+			log.Printf("listening on %s (HTTPS)", cfg.Addr)
+			err = srv.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+		} else {
+			log.Printf("listening on %s (HTTP, TLS not configured)", cfg.Addr)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("listen: %v", err)
+		}
+	}()
+
+	// Wait for Ctrl+C or a termination signal
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	// Let in-flight requests finish before closing the store
+	log.Println("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("graceful shutdown failed, forcing close: %v", err)
+		srv.Close()
+	}
+
+	stopReminders()
+	<-remindersDone
+
+	if err := api.CloseEvents(ctx); err != nil {
+		log.Printf("closing websockets: %v", err)
+	}
+
+	if hooks != nil {
+		if err := hooks.Close(ctx); err != nil {
+			log.Printf("delivering webhooks: %v", err)
+		}
+	}
+}
+
+// openStore opens the SQLite database named by cfg, or an in-memory store
+// when there is none
+func openStore(cfg config.Config) (store.Store, error) {
+	if cfg.DBPath == "" {
+		return store.NewMemoryStore(), nil
+	}
+	return store.NewSQLiteStore(cfg.DBPath)
+}
+
+// newServer returns the HTTP server for handler, listening on cfg.Addr
+func newServer(cfg config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:    cfg.Addr,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+}
 
+// withMiddleware wraps h in mws, the first outermost, as Router.Use does
+func withMiddleware(h http.Handler, mws []mux.MiddlewareFunc) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
 }
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+type contextKey string
+
+const userIDKey contextKey = "userID"
+
+// RequireAuth rejects requests without a valid HS256 bearer token signed with
+// secret. The token subject is stored in the request context as the user id.
+// Browsers cannot set headers on WebSocket handshakes, so those may pass the
+// token in the access_token query parameter instead.
+func RequireAuth(secret []byte) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				raw, ok = r.URL.Query().Get("access_token"), true
+			}
+			if !ok || raw == "" {
+				unauthorized(w, "missing bearer token")
+				return
+			}
+
+			var claims jwt.RegisteredClaims
+			_, err := jwt.ParseWithClaims(raw, &claims, func(*jwt.Token) (any, error) {
+				return secret, nil
+			}, jwt.WithValidMethods([]string{"HS256"}))
+			if err != nil || claims.Subject == "" {
+				unauthorized(w, "invalid token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userIDKey, claims.Subject)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UserID returns the id of the authenticated user, if any
+func UserID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(userIDKey).(string)
+	return id, ok
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeError(w, http.StatusUnauthorized, msg)
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Responses shorter than gzipMinSize are not worth compressing
+const gzipMinSize = 1024
+
+// compressedTypes are content types whose bodies are compressed already, so
+// gzip would spend CPU without making them smaller
+var compressedTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"application/pdf", "application/zip", "application/gzip",
+}
+
+// gzipWriter holds back the response until it knows whether the body is
+// large enough to compress
+type gzipWriter struct {
+	http.ResponseWriter
+	status int
+	buf    []byte
+	gz     *gzip.Writer
+	done   bool
+}
+
+func (g *gzipWriter) WriteHeader(status int) {
+	if g.status == 0 {
+		g.status = status
+	}
+}
+
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	if g.gz != nil {
+		return g.gz.Write(p)
+	}
+	if g.done {
+		return g.ResponseWriter.Write(p)
+	}
+
+	g.buf = append(g.buf, p...)
+	if len(g.buf) >= gzipMinSize {
+		if err := g.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// start sends the headers and the buffered body, compressed or not
+func (g *gzipWriter) start(compress bool) error {
+	g.done = true
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+
+	h := g.Header()
+	if compress && h.Get("Content-Type") == "" {
+		h.Set("Content-Type", http.DetectContentType(g.buf))
+	}
+
+	// Ranges refer to the uncompressed body, so partial responses are sent
+	// as they are
+	if compress && h.Get("Content-Encoding") == "" && h.Get("Content-Range") == "" && !compressedType(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+
+		// The compressed body is another representation, and only a weak
+		// validator may be shared between the two
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+
+		g.ResponseWriter.WriteHeader(g.status)
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+		_, err := g.gz.Write(g.buf)
+		return err
+	}
+
+	g.ResponseWriter.WriteHeader(g.status)
+	_, err := g.ResponseWriter.Write(g.buf)
+	return err
+}
+
+// finish flushes whatever the handler left behind
+func (g *gzipWriter) finish() error {
+	if !g.done {
+		return g.start(false)
+	}
+	if g.gz != nil {
+		return g.gz.Close()
+	}
+	return nil
+}
+
+// Gzip compresses responses of at least gzipMinSize bytes for clients that
+// accept gzip. Protocol upgrades such as WebSocket are passed through.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == "HEAD" || r.Header.Get("Upgrade") != "" || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w}
+		defer func() {
+			// A panicking handler's response is left to Recover. Flushing
+			// the buffer would send a 200 ahead of its 500.
+			if err := recover(); err != nil {
+				panic(err)
+			}
+			gw.finish()
+		}()
+
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// compressedType reports whether contentType is one of compressedTypes
+func compressedType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return contains(compressedTypes, strings.ToLower(strings.TrimSpace(mediaType)))
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			v, err := strconv.ParseFloat(q, 64)
+			return err == nil && v > 0
+		}
+		return true
+	}
+	return false
+}
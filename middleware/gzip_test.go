@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipCompressesLargeResponses(t *testing.T) {
+	body := strings.Repeat("a", 2*gzipMinSize)
+	h := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("decompressed body has %d bytes, want %d", len(got), len(body))
+	}
+}
+
+func TestGzipLeavesSmallResponses(t *testing.T) {
+	h := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "short")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q, want none", got)
+	}
+	if got := rec.Body.String(); got != "short" {
+		t.Errorf("body = %q, want %q", got, "short")
+	}
+}
+
+// A panicking handler must not have its buffered output flushed with a 200
+// before Recover writes the 500
+func TestGzipPanicKeepsRecoverStatus(t *testing.T) {
+	h := Recover(Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	})))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(rec.Body.String(), "partial") {
+		t.Errorf("body %q contains output buffered before the panic", rec.Body.String())
+	}
+}
+
+func TestGzipWeakensETag(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"compressed", strings.Repeat("a", 2*gzipMinSize), `W/"abc"`},
+		{"as is", "short", `"abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"abc"`)
+				io.WriteString(w, tt.body)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("ETag"); got != tt.want {
+				t.Errorf("ETag = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGzipSkipsCompressedTypes(t *testing.T) {
+	for _, contentType := range []string{"image/png", "image/jpeg", "application/pdf", "application/zip; name=a.zip"} {
+		h := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			io.WriteString(w, strings.Repeat("a", 2*gzipMinSize))
+		}))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("%s: Content-Encoding = %q, want none", contentType, got)
+		}
+		if rec.Body.Len() != 2*gzipMinSize {
+			t.Errorf("%s: body has %d bytes, want %d as sent", contentType, rec.Body.Len(), 2*gzipMinSize)
+		}
+	}
+}
@@ -1,21 +1,91 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
 )
 
-func EnableCORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// EnableCORS adds CORS headers to every response. Only the given origins are
+// allowed, or every origin when there are none. Allowed methods are the ones
+// registered on router for the requested path.
+func EnableCORS(router *mux.Router, origins []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if origin, ok := allowOrigin(origins, r.Header.Get("Origin")); ok {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if origin != "*" {
+					w.Header().Add("Vary", "Origin")
+				}
+				// A path no route matches has no methods to allow
+				if methods := RouteMethods(router, r); len(methods) > 0 {
+					w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+				}
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match, Idempotency-Key")
+				w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Limit, X-Offset, X-Request-ID, ETag, Idempotent-Replayed")
+			}
+
+			// Handle preflight requests
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeError writes the same JSON error shape as the controllers
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": msg, "status": status})
+}
+
+// allowOrigin returns the value for Access-Control-Allow-Origin, if any
+func allowOrigin(origins []string, origin string) (string, bool) {
+	if len(origins) == 0 {
+		return "*", true
+	}
+	for _, allowed := range origins {
+		if origin == allowed {
+			return origin, true
+		}
+	}
+	return "", false
+}
 
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// RouteMethods lists the methods of every route registered for the path of r
+func RouteMethods(router *mux.Router, r *http.Request) []string {
+	var methods []string
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		var match mux.RouteMatch
+		if !route.Match(r, &match) && match.MatchErr != mux.ErrMethodMismatch {
+			return nil
 		}
 
-		next.ServeHTTP(w, r)
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, m := range routeMethods {
+			if !contains(methods, m) {
+				methods = append(methods, m)
+			}
+		}
+		return nil
 	})
+	return methods
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
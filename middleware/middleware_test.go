@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestEnableCORSAllowMethods(t *testing.T) {
+	router := mux.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	router.HandleFunc("/notes", ok).Methods("GET", "OPTIONS")
+	router.HandleFunc("/notes", ok).Methods("POST")
+
+	h := EnableCORS(router, nil)(http.HandlerFunc(ok))
+	tests := []struct {
+		path string
+		want string // empty when the header should be left out
+	}{
+		{"/notes", "GET, OPTIONS, POST"},
+		{"/nowhere", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("OPTIONS", tt.path, nil)
+		req.Header.Set("Origin", "https://app.example")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		got, set := rec.Header()["Access-Control-Allow-Methods"]
+		if set != (tt.want != "") || (set && got[0] != tt.want) {
+			t.Errorf("%s: Access-Control-Allow-Methods = %q, want %q", tt.path, got, tt.want)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want *", tt.path, rec.Header().Get("Access-Control-Allow-Origin"))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/time/rate"
+)
+
+// Clients that have not been seen for limiterIdle lose their limiter
+const limiterIdle = 3 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimit allows each client IP rps requests per second with bursts of up
+// to burst requests, and answers 429 beyond that. With trustProxy set the
+// client IP is taken from X-Forwarded-For. Requests for the exempt paths are
+// never limited, so probes and scrapers sharing an IP with clients keep
+// getting through.
+func RateLimit(rps float64, burst int, trustProxy bool, exempt ...string) mux.MiddlewareFunc {
+	var mu sync.Mutex
+	clients := map[string]*clientLimiter{}
+
+	// Evict idle clients so the map does not grow without bound
+	go func() {
+		for range time.Tick(time.Minute) {
+			mu.Lock()
+			for ip, c := range clients {
+				if time.Since(c.lastSeen) > limiterIdle {
+					delete(clients, ip)
+				}
+			}
+			mu.Unlock()
+		}
+	}()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if contains(exempt, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ip := clientIP(r, trustProxy)
+
+			mu.Lock()
+			c, ok := clients[ip]
+			if !ok {
+				c = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+				clients[ip] = c
+			}
+			c.lastSeen = time.Now()
+			res := c.limiter.Reserve()
+			mu.Unlock()
+
+			if delay := res.Delay(); delay > 0 {
+				res.Cancel()
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the address of the client. Behind a proxy this is the
+// last X-Forwarded-For entry, the one added by the proxy itself.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			parts := strings.Split(fwd, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
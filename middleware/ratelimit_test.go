@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimitExemptPaths(t *testing.T) {
+	h := RateLimit(1, 1, false, "/health")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	get := func(path string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := get("/notes"); code != http.StatusOK {
+		t.Fatalf("first request: status = %d, want %d", code, http.StatusOK)
+	}
+	if code := get("/notes"); code != http.StatusTooManyRequests {
+		t.Errorf("second request: status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	for i := 0; i < 5; i++ {
+		if code := get("/health"); code != http.StatusOK {
+			t.Fatalf("/health after the limit: status = %d, want %d", code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover turns a panic in any later handler into a 500 response instead of
+// letting it crash the server
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				// Let net/http deal with deliberate connection aborts
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				log.Printf("panic serving %s %s (request_id=%s): %v\n%s",
+					r.Method, r.URL.Path, RequestIDFrom(r.Context()), err, debug.Stack())
+
+				writeError(w, http.StatusInternalServerError, "internal server error")
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRecoverKeepsServing panics in a handler behind the same chain main.go
+// uses and checks that the client gets a JSON 500, with and without gzip,
+// and that the server goes on answering requests
+func TestRecoverKeepsServing(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		panic("deliberate")
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+
+	srv := httptest.NewServer(RequestID(Recover(RequestLogger(Gzip(mux)))))
+	defer srv.Close()
+
+	for _, encoding := range []string{"", "gzip"} {
+		req, _ := http.NewRequest("GET", srv.URL+"/panic", nil)
+		if encoding != "" {
+			req.Header.Set("Accept-Encoding", encoding)
+		}
+
+		// DisableCompression keeps the transport from asking for gzip itself
+		client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Accept-Encoding %q: %v", encoding, err)
+		}
+
+		var body errorBody
+		err = json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close()
+		if res.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Accept-Encoding %q: status = %d, want %d", encoding, res.StatusCode, http.StatusInternalServerError)
+		}
+		if err != nil || body.Status != http.StatusInternalServerError {
+			t.Errorf("Accept-Encoding %q: body = %+v (%v), want a JSON 500 error", encoding, body, err)
+		}
+	}
+
+	res, err := http.Get(srv.URL + "/ok")
+	if err != nil {
+		t.Fatalf("server stopped answering after a panic: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status after a panic = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+}
+
+func TestRecoverRepanicsAbortHandler(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", err)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+type errorBody struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
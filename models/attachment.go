@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Attachment describes a file uploaded to a note. The file itself is kept
+// outside the store, under StorageKey.
+type Attachment struct {
+	ID          int64     `json:"id"`
+	NoteID      int64     `json:"note_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	StorageKey  string    `json:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxFolderNameLength is the longest folder name Validate accepts, in
+// characters
+const MaxFolderNameLength = 100
+
+var (
+	ErrFolderNameRequired = errors.New("folder name is required")
+	ErrFolderNameTooLong  = fmt.Errorf("folder name must be at most %d characters", MaxFolderNameLength)
+)
+
+// Folder groups notes. Folders without a parent sit at the root.
+type Folder struct {
+	ID        int64     `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	Name      string    `json:"name"`
+	ParentID  *int64    `json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewFolder returns a folder with its timestamps set to now, in UTC
+func NewFolder(name string, parentID *int64) Folder {
+	now := time.Now().UTC()
+	return Folder{
+		Name:      name,
+		ParentID:  parentID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate trims the name and checks it against the length limit
+func (f *Folder) Validate() error {
+	f.Name = strings.TrimSpace(f.Name)
+
+	switch {
+	case f.Name == "":
+		return ErrFolderNameRequired
+	case utf8.RuneCountInString(f.Name) > MaxFolderNameLength:
+		return ErrFolderNameTooLong
+	}
+	return nil
+}
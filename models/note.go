@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits enforced by Validate, counted in characters
+const (
+	MaxTitleLength   = 200
+	MaxContentLength = 100000
+	MaxTagLength     = 50
+)
+
+var (
+	ErrTitleRequired  = errors.New("title is required")
+	ErrTitleTooLong   = fmt.Errorf("title must be at most %d characters", MaxTitleLength)
+	ErrContentTooLong = fmt.Errorf("content must be at most %d characters", MaxContentLength)
+	ErrTagTooLong     = fmt.Errorf("tags must be at most %d characters", MaxTagLength)
+)
+
+// Note is a single note as stored and returned by the API
+type Note struct {
+	ID        int64      `json:"id"`
+	OwnerID   string     `json:"owner_id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Tags      []string   `json:"tags"`
+	FolderID  *int64     `json:"folder_id"`
+	Pinned    bool       `json:"pinned"`
+	Archived  bool       `json:"archived"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// RemindAt is when the note falls due, if ever. Changing it clears
+	// ReminderAcknowledged.
+	RemindAt             *time.Time `json:"remind_at"`
+	ReminderAcknowledged bool       `json:"reminder_acknowledged"`
+}
+
+// NoteVersion is a snapshot of a note taken just before it was updated
+type NoteVersion struct {
+	Version   int       `json:"version"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewNote returns a note with its timestamps set to now, in UTC
+func NewNote(title, content string) Note {
+	now := time.Now().UTC()
+	return Note{
+		Title:     title,
+		Content:   content,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate trims the title and tags and checks the note against the field
+// limits. Blank and repeated tags are dropped, and the reminder is moved to
+// UTC.
+func (n *Note) Validate() error {
+	n.Title = strings.TrimSpace(n.Title)
+	if n.RemindAt != nil {
+		remindAt := n.RemindAt.UTC()
+		n.RemindAt = &remindAt
+	}
+
+	tags := []string{}
+	for _, tag := range n.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return ErrTagTooLong
+		}
+		tags = append(tags, tag)
+	}
+	n.Tags = tags
+
+	switch {
+	case n.Title == "":
+		return ErrTitleRequired
+	case utf8.RuneCountInString(n.Title) > MaxTitleLength:
+		return ErrTitleTooLong
+	case utf8.RuneCountInString(n.Content) > MaxContentLength:
+		return ErrContentTooLong
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Share describes a read-only link to a note. The token is only known when
+// the share is created.
+type Share struct {
+	NoteID    int64      `json:"note_id"`
+	Token     string     `json:"token"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// SharedNote is the public view of a shared note. It leaves out the owner
+// and every field that only makes sense to them.
+type SharedNote struct {
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewSharedNote returns the public view of n
+func NewSharedNote(n Note) SharedNote {
+	return SharedNote{
+		Title:     n.Title,
+		Content:   n.Content,
+		Tags:      n.Tags,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+	}
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// EventType names the kind of change an Event reports
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+
+	// EventReminder is published when the reminder of a note falls due
+	EventReminder EventType = "reminder"
+)
+
+// Event reports a change to a note. Note is the note after the change and
+// is left out for deletions.
+type Event struct {
+	Type    EventType    `json:"type"`
+	NoteID  int64        `json:"note_id"`
+	Note    *models.Note `json:"note,omitempty"`
+	OwnerID string       `json:"-"`
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// it is dropped
+const subscriberBuffer = 64
+
+// Hub fans events out to subscribers, each of which only receives the
+// events of one owner. It is safe for concurrent use.
+type Hub struct {
+	mu        sync.Mutex
+	subs      map[chan Event]string
+	observers []func(Event)
+	closed    bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[chan Event]string{}}
+}
+
+// Subscribe returns a channel receiving the events of ownerID, and a
+// function that ends the subscription. The channel is closed when the
+// subscription ends, when the subscriber falls too far behind, or when the
+// hub is closed.
+func (h *Hub) Subscribe(ownerID string) (<-chan Event, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	h.subs[ch] = ownerID
+	return ch, func() { h.unsubscribe(ch) }
+}
+
+// Observe has fn called with every event, whatever its owner. Unlike
+// subscribers, observers are never dropped, so fn is called while the event
+// is published and must not block.
+func (h *Hub) Observe(fn func(Event)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.observers = append(h.observers, fn)
+}
+
+func (h *Hub) unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// Publish sends ev to every subscriber of its owner without blocking.
+// Subscribers whose buffer is full are dropped rather than sent a partial
+// stream, so they can reconnect and reload.
+func (h *Hub) Publish(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, fn := range h.observers {
+		fn(ev)
+	}
+	for ch, ownerID := range h.subs {
+		if ownerID != ev.OwnerID {
+			continue
+		}
+		select {
+		case ch <- ev:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription. Later subscriptions end immediately.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+	h.closed = true
+}
+
+// eventStore publishes an Event for every change made through it
+type eventStore struct {
+	Store
+	hub *Hub
+}
+
+// WithEvents wraps s so that every successful create, update and delete is
+// published to hub, imported notes included. Purges are not published.
+// Reminders falling due are published by whoever watches for them.
+func WithEvents(s Store, hub *Hub) Store {
+	return &eventStore{Store: s, hub: hub}
+}
+
+func (s *eventStore) publish(t EventType, n models.Note) {
+	s.hub.Publish(Event{Type: t, NoteID: n.ID, Note: &n, OwnerID: n.OwnerID})
+}
+
+func (s *eventStore) Create(ctx context.Context, n models.Note) (models.Note, error) {
+	n, err := s.Store.Create(ctx, n)
+	if err == nil {
+		s.publish(EventCreated, n)
+	}
+	return n, err
+}
+
+func (s *eventStore) CreateWith(ctx context.Context, n models.Note, opts CreateOptions) (models.Note, bool, error) {
+	n, created, err := s.Store.CreateWith(ctx, n, opts)
+	if err == nil && created {
+		s.publish(EventCreated, n)
+	}
+	return n, created, err
+}
+
+func (s *eventStore) CreateMany(ctx context.Context, notes []models.Note) ([]models.Note, error) {
+	created, err := s.Store.CreateMany(ctx, notes)
+	if err == nil {
+		for _, n := range created {
+			s.publish(EventCreated, n)
+		}
+	}
+	return created, err
+}
+
+func (s *eventStore) Import(ctx context.Context, notes []models.Note, preserveIDs bool) ([]models.Note, int, error) {
+	imported, skipped, err := s.Store.Import(ctx, notes, preserveIDs)
+	if err == nil {
+		for _, n := range imported {
+			s.publish(EventCreated, n)
+		}
+	}
+	return imported, skipped, err
+}
+
+func (s *eventStore) Update(ctx context.Context, n models.Note) (models.Note, error) {
+	n, err := s.Store.Update(ctx, n)
+	if err == nil {
+		s.publish(EventUpdated, n)
+	}
+	return n, err
+}
+
+func (s *eventStore) SetPinned(ctx context.Context, ownerID string, id int64, pinned bool) (models.Note, error) {
+	n, err := s.Store.SetPinned(ctx, ownerID, id, pinned)
+	if err == nil {
+		s.publish(EventUpdated, n)
+	}
+	return n, err
+}
+
+func (s *eventStore) SetArchived(ctx context.Context, ownerID string, id int64, archived bool) (models.Note, error) {
+	n, err := s.Store.SetArchived(ctx, ownerID, id, archived)
+	if err == nil {
+		s.publish(EventUpdated, n)
+	}
+	return n, err
+}
+
+func (s *eventStore) AcknowledgeReminder(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	n, err := s.Store.AcknowledgeReminder(ctx, ownerID, id)
+	if err == nil {
+		s.publish(EventUpdated, n)
+	}
+	return n, err
+}
+
+func (s *eventStore) Delete(ctx context.Context, ownerID string, id int64) error {
+	err := s.Store.Delete(ctx, ownerID, id)
+	if err == nil {
+		s.hub.Publish(Event{Type: EventDeleted, NoteID: id, OwnerID: ownerID})
+	}
+	return err
+}
+
+func (s *eventStore) DeleteMany(ctx context.Context, ownerID string, ids []int64) ([]int64, []int64, error) {
+	deleted, notFound, err := s.Store.DeleteMany(ctx, ownerID, ids)
+	if err == nil {
+		for _, id := range deleted {
+			s.hub.Publish(Event{Type: EventDeleted, NoteID: id, OwnerID: ownerID})
+		}
+	}
+	return deleted, notFound, err
+}
+
+// Restore brings a note back into view, so it is announced as created
+func (s *eventStore) Restore(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	n, err := s.Store.Restore(ctx, ownerID, id)
+	if err == nil {
+		s.publish(EventCreated, n)
+	}
+	return n, err
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// TestWithEventsPublishesImports checks that every imported note is
+// announced as created, and that skipped notes are not
+func TestWithEventsPublishesImports(t *testing.T) {
+	hub := NewHub()
+	defer hub.Close()
+	events, stop := hub.Subscribe("alice")
+	defer stop()
+
+	s := WithEvents(NewMemoryStore(), hub)
+	ctx := context.Background()
+
+	existing := models.NewNote("existing", "")
+	existing.OwnerID = "alice"
+	existing, err := s.Create(ctx, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Type != EventCreated || ev.NoteID != existing.ID {
+		t.Fatalf("event = %+v, want the creation of note %d", ev, existing.ID)
+	}
+
+	var notes []models.Note
+	for _, title := range []string{"one", "two"} {
+		n := models.NewNote(title, "")
+		n.OwnerID = "alice"
+		notes = append(notes, n)
+	}
+	skip := models.NewNote("skipped", "")
+	skip.OwnerID = "alice"
+	skip.ID = existing.ID
+	notes = append(notes, skip)
+
+	imported, skipped, err := s.Import(ctx, notes, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 2 || skipped != 1 {
+		t.Fatalf("imported, skipped = %d, %d, want 2, 1", len(imported), skipped)
+	}
+
+	for _, n := range imported {
+		ev := <-events
+		if ev.Type != EventCreated || ev.NoteID != n.ID || ev.Note == nil || ev.Note.Title != n.Title {
+			t.Errorf("event = %+v, want the creation of note %d", ev, n.ID)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected event %+v", ev)
+	default:
+	}
+}
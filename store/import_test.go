@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// TestImportPreservedIDs checks that a preserved id already in use is
+// skipped, whoever owns it, and that a free one is kept
+func TestImportPreservedIDs(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqlite} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			note := func(owner, title string) models.Note {
+				n := models.NewNote(title, "")
+				n.OwnerID = owner
+				return n
+			}
+
+			mine, err := s.Create(ctx, note("alice", "mine"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			theirs, err := s.Create(ctx, note("bob", "theirs"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			again, clash, fresh := note("alice", "again"), note("alice", "clash"), note("alice", "fresh")
+			again.ID, clash.ID, fresh.ID = mine.ID, theirs.ID, theirs.ID+10
+
+			imported, skipped, err := s.Import(ctx, []models.Note{again, clash, fresh}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(imported) != 1 || skipped != 2 {
+				t.Errorf("imported, skipped = %d, %d, want 1, 2", len(imported), skipped)
+			}
+
+			if n, err := s.Get(ctx, "bob", theirs.ID); err != nil || n.Title != "theirs" {
+				t.Errorf("bob's note = %+v, %v, want it unchanged", n, err)
+			}
+			if n, err := s.Get(ctx, "alice", fresh.ID); err != nil || n.Title != "fresh" {
+				t.Errorf("note %d = %+v, %v, want the imported note with its id", fresh.ID, n, err)
+			}
+
+			notes, _, err := s.List(ctx, ListOptions{OwnerID: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(notes) != 2 {
+				t.Errorf("alice has %d notes, want her own and the fresh import", len(notes))
+			}
+		})
+	}
+}
+
+// TestImportSortsAcrossTimezones imports notes exported with times in a
+// zone east of UTC and checks that they sort by the instant, not the text
+func TestImportSortsAcrossTimezones(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	nairobi := time.FixedZone("EAT", 3*60*60)
+	// 10:00 here is 07:00 UTC, earlier than 08:00 UTC despite reading later
+	early := time.Date(2026, 1, 2, 10, 0, 0, 0, nairobi)
+	late := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqlite} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			var notes []models.Note
+			for title, at := range map[string]time.Time{"late": late, "early": early} {
+				n := models.NewNote(title, "")
+				n.OwnerID = "alice"
+				n.CreatedAt, n.UpdatedAt = at, at
+				notes = append(notes, n)
+			}
+			if _, _, err := s.Import(ctx, notes, false); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, field := range []string{SortCreatedAt, SortUpdatedAt} {
+				listed, _, err := s.List(ctx, ListOptions{OwnerID: "alice", Sort: Sort{Field: field}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(listed) != 2 || listed[0].Title != "early" || listed[1].Title != "late" {
+					t.Errorf("sort=%s: got %+v, want early then late", field, listed)
+				}
+				if !listed[0].CreatedAt.Equal(early) || listed[0].CreatedAt.Location() != time.UTC {
+					t.Errorf("created_at = %s, want %s in UTC", listed[0].CreatedAt, early.UTC())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// TestListFoldsUnicodeCase checks that both stores match tags and search
+// text, and sort titles, with the same case folding beyond ASCII
+func TestListFoldsUnicodeCase(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	for name, s := range map[string]Store{"memory": NewMemoryStore(), "sqlite": sqlite} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, n := range []struct{ title, content, tag string }{
+				{"Été à Paris", "Crème brûlée", "Été"},
+				{"zèbre", "rayures", "animaux"},
+				{"Hiver", "100% neige_fraîche", "ÉTÉ-2"},
+			} {
+				note := models.NewNote(n.title, n.content)
+				note.OwnerID = "alice"
+				note.Tags = []string{n.tag}
+				if _, err := s.Create(ctx, note); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tests := []struct {
+				name string
+				opts ListOptions
+				want []string
+			}{
+				{"tag", ListOptions{Tags: []string{"été"}}, []string{"Été à Paris"}},
+				{"tag upper", ListOptions{Tags: []string{"ÉTÉ"}}, []string{"Été à Paris"}},
+				{"query title", ListOptions{Query: "ÉTÉ"}, []string{"Été à Paris"}},
+				{"query content", ListOptions{Query: "CRÈME"}, []string{"Été à Paris"}},
+				{"query wildcards", ListOptions{Query: "% NEIGE_"}, []string{"Hiver"}},
+				{"query no wildcard", ListOptions{Query: "100_"}, nil},
+				{"title sort", ListOptions{Sort: Sort{Field: SortTitle}}, []string{"Hiver", "zèbre", "Été à Paris"}},
+			}
+			for _, tt := range tests {
+				tt.opts.OwnerID = "alice"
+				notes, _, err := s.List(ctx, tt.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var titles []string
+				for _, n := range notes {
+					titles = append(titles, n.Title)
+				}
+				if !slices.Equal(titles, tt.want) {
+					t.Errorf("%s: got %q, want %q", tt.name, titles, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,824 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// MemoryStore keeps notes in a map and is safe for concurrent use
+type MemoryStore struct {
+	mu          sync.RWMutex
+	notes       map[int64]models.Note
+	versions    map[int64][]models.NoteVersion
+	idempotency map[idempotencyKey]idempotentNote
+	nextID      int64
+
+	folders      map[int64]models.Folder
+	nextFolderID int64
+
+	// shares maps note ids to their share link
+	shares map[int64]noteShare
+
+	attachments      map[int64]models.Attachment
+	nextAttachmentID int64
+}
+
+type noteShare struct {
+	tokenHash string
+	expiresAt *time.Time
+}
+
+// idempotencyKey identifies a key; keys are only unique per owner
+type idempotencyKey struct {
+	ownerID string
+	key     string
+}
+
+// idempotentNote is the note created for an idempotency key
+type idempotentNote struct {
+	note      models.Note
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		notes:       map[int64]models.Note{},
+		versions:    map[int64][]models.NoteVersion{},
+		idempotency: map[idempotencyKey]idempotentNote{},
+		nextID:      1,
+
+		folders:      map[int64]models.Folder{},
+		nextFolderID: 1,
+
+		shares: map[int64]noteShare{},
+
+		attachments:      map[int64]models.Attachment{},
+		nextAttachmentID: 1,
+	}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, n models.Note) (models.Note, error) {
+	created, err := s.CreateMany(ctx, []models.Note{n})
+	if err != nil {
+		return models.Note{}, err
+	}
+	return created[0], nil
+}
+
+func (s *MemoryStore) CreateWith(ctx context.Context, n models.Note, opts CreateOptions) (models.Note, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := idempotencyKey{ownerID: n.OwnerID, key: opts.Key}
+	if opts.Key != "" {
+		now := time.Now()
+		for key, v := range s.idempotency {
+			if !v.expiresAt.After(now) {
+				delete(s.idempotency, key)
+			}
+		}
+
+		if v, ok := s.idempotency[k]; ok {
+			v.note.Tags = slices.Clone(v.note.Tags)
+			return v.note, false, nil
+		}
+	}
+
+	if opts.Dedupe {
+		if id, ok := s.duplicateOf(n); ok {
+			return models.Note{}, false, &DuplicateError{ID: id}
+		}
+	}
+
+	n.ID = s.nextID
+	n.Tags = slices.Clone(n.Tags)
+	s.nextID++
+	s.notes[n.ID] = n
+	if opts.Key != "" {
+		s.idempotency[k] = idempotentNote{note: n, expiresAt: opts.ExpiresAt}
+	}
+	return n, true, nil
+}
+
+// duplicateOf returns the oldest note of n's owner with the same trimmed
+// title and content. The caller must hold s.mu.
+func (s *MemoryStore) duplicateOf(n models.Note) (int64, bool) {
+	title, content := strings.TrimSpace(n.Title), strings.TrimSpace(n.Content)
+	var found int64
+	for id, other := range s.notes {
+		if other.OwnerID != n.OwnerID || other.DeletedAt != nil || (found != 0 && id > found) {
+			continue
+		}
+		if strings.TrimSpace(other.Title) == title && strings.TrimSpace(other.Content) == content {
+			found = id
+		}
+	}
+	return found, found != 0
+}
+
+func (s *MemoryStore) CreateMany(ctx context.Context, notes []models.Note) ([]models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := make([]models.Note, 0, len(notes))
+	for _, n := range notes {
+		n.ID = s.nextID
+		n.Tags = slices.Clone(n.Tags)
+		s.nextID++
+		s.notes[n.ID] = n
+		created = append(created, n)
+	}
+	return created, nil
+}
+
+func (s *MemoryStore) Import(ctx context.Context, notes []models.Note, preserveIDs bool) ([]models.Note, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	imported, skipped := make([]models.Note, 0, len(notes)), 0
+	for _, n := range notes {
+		if !preserveIDs {
+			n.ID = 0
+		} else if _, taken := s.notes[n.ID]; taken {
+			skipped++
+			continue
+		}
+
+		if n.ID > 0 {
+			s.nextID = max(s.nextID, n.ID+1)
+		} else {
+			n.ID = s.nextID
+			s.nextID++
+		}
+
+		n.CreatedAt, n.UpdatedAt = n.CreatedAt.UTC(), n.UpdatedAt.UTC()
+		n.Tags = slices.Clone(n.Tags)
+		s.notes[n.ID] = n
+		imported = append(imported, n)
+	}
+	return imported, skipped, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return models.Note{}, ErrNotFound
+	}
+	return n, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, opts ListOptions) ([]models.Note, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	s.mu.RLock()
+	list := make([]models.Note, 0, len(s.notes))
+	for _, n := range s.notes {
+		if matches(n, opts) {
+			list = append(list, n)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return less(list[i], list[j], opts.Sort) })
+	return paginate(list, opts), len(list), nil
+}
+
+func (s *MemoryStore) Count(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, n := range s.notes {
+		if n.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Update replaces the stored note with n, keeping its creation time
+func (s *MemoryStore) Update(ctx context.Context, n models.Note) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.notes[n.ID]
+	if !ok || old.OwnerID != n.OwnerID || old.DeletedAt != nil {
+		return models.Note{}, ErrNotFound
+	}
+
+	s.versions[n.ID] = append(s.versions[n.ID], models.NoteVersion{
+		Version:   len(s.versions[n.ID]) + 1,
+		Title:     old.Title,
+		Content:   old.Content,
+		Tags:      old.Tags,
+		UpdatedAt: old.UpdatedAt,
+	})
+
+	n.Tags = slices.Clone(n.Tags)
+	n.Pinned = old.Pinned
+	n.Archived = old.Archived
+	n.ReminderAcknowledged = old.ReminderAcknowledged && sameTime(old.RemindAt, n.RemindAt)
+	n.CreatedAt = old.CreatedAt
+	n.UpdatedAt = time.Now().UTC()
+	n.DeletedAt = nil
+	s.notes[n.ID] = n
+	return n, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, ownerID string, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	n.DeletedAt = &now
+	s.notes[id] = n
+	return nil
+}
+
+func (s *MemoryStore) DeleteMany(ctx context.Context, ownerID string, ids []int64) ([]int64, []int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	deleted, notFound := []int64{}, []int64{}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		n, ok := s.notes[id]
+		if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+			notFound = append(notFound, id)
+			continue
+		}
+		n.DeletedAt = &now
+		s.notes[id] = n
+		deleted = append(deleted, id)
+	}
+	return deleted, notFound, nil
+}
+
+func (s *MemoryStore) SetPinned(ctx context.Context, ownerID string, id int64, pinned bool) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return models.Note{}, ErrNotFound
+	}
+
+	n.Pinned = pinned
+	s.notes[id] = n
+	return n, nil
+}
+
+func (s *MemoryStore) SetArchived(ctx context.Context, ownerID string, id int64, archived bool) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return models.Note{}, ErrNotFound
+	}
+
+	n.Archived = archived
+	s.notes[id] = n
+	return n, nil
+}
+
+func (s *MemoryStore) Restore(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt == nil {
+		return models.Note{}, ErrNotFound
+	}
+
+	n.DeletedAt = nil
+	s.notes[id] = n
+	return n, nil
+}
+
+func (s *MemoryStore) Purge(ctx context.Context, ownerID string, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt == nil {
+		return ErrNotFound
+	}
+
+	delete(s.notes, id)
+	delete(s.versions, id)
+	delete(s.shares, id)
+	for aid, a := range s.attachments {
+		if a.NoteID == id {
+			delete(s.attachments, aid)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Versions(ctx context.Context, ownerID string, id int64) ([]models.NoteVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return append([]models.NoteVersion{}, s.versions[id]...), nil
+}
+
+func (s *MemoryStore) Version(ctx context.Context, ownerID string, id int64, version int) (models.NoteVersion, error) {
+	versions, err := s.Versions(ctx, ownerID, id)
+	if err != nil {
+		return models.NoteVersion{}, err
+	}
+	if version < 1 || version > len(versions) {
+		return models.NoteVersion{}, ErrVersionNotFound
+	}
+	return versions[version-1], nil
+}
+
+func (s *MemoryStore) Reminders(ctx context.Context, opts ReminderOptions) ([]models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	due := []models.Note{}
+	for _, n := range s.notes {
+		switch {
+		case n.RemindAt == nil, n.ReminderAcknowledged, n.DeletedAt != nil:
+			continue
+		case opts.OwnerID != "" && n.OwnerID != opts.OwnerID:
+			continue
+		case opts.After != nil && !n.RemindAt.After(*opts.After):
+			continue
+		case n.RemindAt.After(opts.Before):
+			continue
+		}
+		n.Tags = slices.Clone(n.Tags)
+		due = append(due, n)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].RemindAt.Equal(*due[j].RemindAt) {
+			return due[i].RemindAt.Before(*due[j].RemindAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	return due, nil
+}
+
+func (s *MemoryStore) AcknowledgeReminder(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return models.Note{}, ErrNotFound
+	}
+	if n.RemindAt == nil {
+		return models.Note{}, ErrNoReminder
+	}
+
+	n.ReminderAcknowledged = true
+	s.notes[id] = n
+	return n, nil
+}
+
+func (s *MemoryStore) ShareNote(ctx context.Context, ownerID string, id int64, tokenHash string, expiresAt *time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return ErrNotFound
+	}
+
+	s.shares[id] = noteShare{tokenHash: tokenHash, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemoryStore) UnshareNote(ctx context.Context, ownerID string, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[id]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if _, ok := s.shares[id]; !ok {
+		return ErrShareNotFound
+	}
+
+	delete(s.shares, id)
+	return nil
+}
+
+func (s *MemoryStore) SharedNote(ctx context.Context, tokenHash string) (models.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Note{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for id, share := range s.shares {
+		if share.tokenHash != tokenHash {
+			continue
+		}
+		if share.expiresAt != nil && !share.expiresAt.After(time.Now()) {
+			break
+		}
+		if n := s.notes[id]; n.DeletedAt == nil {
+			return n, nil
+		}
+		break
+	}
+	return models.Note{}, ErrShareNotFound
+}
+
+func (s *MemoryStore) CreateAttachment(ctx context.Context, ownerID string, a models.Attachment) (models.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Attachment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notes[a.NoteID]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return models.Attachment{}, ErrNotFound
+	}
+
+	a.ID = s.nextAttachmentID
+	s.nextAttachmentID++
+	s.attachments[a.ID] = a
+	return a, nil
+}
+
+func (s *MemoryStore) Attachments(ctx context.Context, ownerID string, noteID int64) ([]models.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.notes[noteID]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	list := []models.Attachment{}
+	for _, a := range s.attachments {
+		if a.NoteID == noteID {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (s *MemoryStore) Attachment(ctx context.Context, ownerID string, noteID, id int64) (models.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Attachment{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.notes[noteID]
+	if !ok || n.OwnerID != ownerID || n.DeletedAt != nil {
+		return models.Attachment{}, ErrNotFound
+	}
+
+	a, ok := s.attachments[id]
+	if !ok || a.NoteID != noteID {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	return a, nil
+}
+
+func (s *MemoryStore) CreateFolder(ctx context.Context, f models.Folder) (models.Folder, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Folder{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.ParentID != nil && !s.ownsFolder(f.OwnerID, *f.ParentID) {
+		return models.Folder{}, ErrParentNotFound
+	}
+
+	f.ID = s.nextFolderID
+	s.nextFolderID++
+	s.folders[f.ID] = f
+	return f, nil
+}
+
+func (s *MemoryStore) GetFolder(ctx context.Context, ownerID string, id int64) (models.Folder, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Folder{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.folders[id]
+	if !ok || f.OwnerID != ownerID {
+		return models.Folder{}, ErrFolderNotFound
+	}
+	return f, nil
+}
+
+func (s *MemoryStore) ListFolders(ctx context.Context, ownerID string) ([]models.Folder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	list := []models.Folder{}
+	for _, f := range s.folders {
+		if f.OwnerID == ownerID {
+			list = append(list, f)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(list, func(a, b models.Folder) int {
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return list, nil
+}
+
+// UpdateFolder replaces the name and parent of the stored folder, keeping
+// its creation time
+func (s *MemoryStore) UpdateFolder(ctx context.Context, f models.Folder) (models.Folder, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Folder{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.folders[f.ID]
+	if !ok || old.OwnerID != f.OwnerID {
+		return models.Folder{}, ErrFolderNotFound
+	}
+
+	// Walk up from the new parent; reaching f means it would be its own
+	// ancestor
+	for parent := f.ParentID; parent != nil; parent = s.folders[*parent].ParentID {
+		if *parent == f.ID {
+			return models.Folder{}, ErrFolderCycle
+		}
+		if !s.ownsFolder(f.OwnerID, *parent) {
+			return models.Folder{}, ErrParentNotFound
+		}
+	}
+
+	f.CreatedAt = old.CreatedAt
+	f.UpdatedAt = time.Now().UTC()
+	s.folders[f.ID] = f
+	return f, nil
+}
+
+func (s *MemoryStore) DeleteFolder(ctx context.Context, ownerID string, id int64, mode FolderDeleteMode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ownsFolder(ownerID, id) {
+		return ErrFolderNotFound
+	}
+
+	if mode == FolderDeleteReject {
+		for _, n := range s.notes {
+			if inFolder(n.FolderID, id) && n.DeletedAt == nil {
+				return ErrFolderNotEmpty
+			}
+		}
+		for _, f := range s.folders {
+			if inFolder(f.ParentID, id) {
+				return ErrFolderNotEmpty
+			}
+		}
+	}
+
+	for noteID, n := range s.notes {
+		if inFolder(n.FolderID, id) {
+			n.FolderID = nil
+			s.notes[noteID] = n
+		}
+	}
+	for folderID, f := range s.folders {
+		if inFolder(f.ParentID, id) {
+			f.ParentID = nil
+			s.folders[folderID] = f
+		}
+	}
+	delete(s.folders, id)
+	return nil
+}
+
+// ownsFolder reports whether folder id exists and belongs to ownerID. The
+// caller must hold s.mu.
+func (s *MemoryStore) ownsFolder(ownerID string, id int64) bool {
+	f, ok := s.folders[id]
+	return ok && f.OwnerID == ownerID
+}
+
+// inFolder reports whether folderID points at folder id
+func inFolder(folderID *int64, id int64) bool {
+	return folderID != nil && *folderID == id
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// matches reports whether n passes the filters in opts
+func matches(n models.Note, opts ListOptions) bool {
+	if n.OwnerID != opts.OwnerID {
+		return false
+	}
+	if n.DeletedAt != nil && !opts.IncludeDeleted {
+		return false
+	}
+
+	if opts.FolderID != nil && !inFolder(n.FolderID, *opts.FolderID) {
+		return false
+	}
+
+	switch opts.Archived {
+	case ArchivedExclude:
+		if n.Archived {
+			return false
+		}
+	case ArchivedOnly:
+		if !n.Archived {
+			return false
+		}
+	}
+
+	for _, tag := range opts.Tags {
+		want := strings.ToLower(tag)
+		if !slices.ContainsFunc(n.Tags, func(t string) bool { return strings.ToLower(t) == want }) {
+			return false
+		}
+	}
+
+	if opts.Query != "" {
+		q := strings.ToLower(opts.Query)
+		if !strings.Contains(strings.ToLower(n.Title), q) && !strings.Contains(strings.ToLower(n.Content), q) {
+			return false
+		}
+	}
+	return true
+}
+
+// less orders a before b according to by
+func less(a, b models.Note, by Sort) bool {
+	if by.PinnedFirst && a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+
+	var c int
+	switch by.Field {
+	case SortCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case SortUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortTitle:
+		c = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+
+	if by.Desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// paginate cuts the page described by opts out of list
+func paginate(list []models.Note, opts ListOptions) []models.Note {
+	if opts.Offset >= len(list) {
+		return []models.Note{}
+	}
+	list = list[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(list) {
+		list = list[:opts.Limit]
+	}
+	return list
+}
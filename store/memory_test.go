@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aminofabian/notes/models"
+)
+
+// TestMemoryStoreConcurrent runs many writers and readers at once; run it
+// with -race to check the locking
+func TestMemoryStoreConcurrent(t *testing.T) {
+	const (
+		owners    = 4
+		workers   = 8
+		perWorker = 24
+	)
+
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, owners*workers)
+	for o := 0; o < owners; o++ {
+		owner := fmt.Sprintf("user%d", o)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := exerciseStore(ctx, s, owner, perWorker); err != nil {
+					errs <- fmt.Errorf("%s: %w", owner, err)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Every worker deletes every other note it created
+	for o := 0; o < owners; o++ {
+		owner := fmt.Sprintf("user%d", o)
+		notes, total, err := s.List(ctx, ListOptions{OwnerID: owner})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := workers * perWorker / 2
+		if total != want || len(notes) != want {
+			t.Errorf("%s has %d notes (total %d), want %d", owner, len(notes), total, want)
+		}
+		for _, n := range notes {
+			if n.OwnerID != owner {
+				t.Errorf("%s listed note %d of %s", owner, n.ID, n.OwnerID)
+			}
+			if n.Content != "edited" {
+				t.Errorf("note %d has content %q, want the update", n.ID, n.Content)
+			}
+		}
+	}
+
+	count, err := s.Count(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := owners * workers * perWorker / 2; count != want {
+		t.Errorf("Count = %d, want %d", count, want)
+	}
+}
+
+// exerciseStore creates n notes for owner, reading, listing and updating
+// each of them along the way, and deletes every other one
+func exerciseStore(ctx context.Context, s Store, owner string, n int) error {
+	for i := 0; i < n; i++ {
+		note := models.NewNote(fmt.Sprintf("note %d", i), "draft")
+		note.OwnerID = owner
+
+		created, err := s.Create(ctx, note)
+		if err != nil {
+			return err
+		}
+
+		got, err := s.Get(ctx, owner, created.ID)
+		if err != nil {
+			return fmt.Errorf("get %d: %w", created.ID, err)
+		}
+		if got.Title != note.Title {
+			return fmt.Errorf("get %d: title %q, want %q", created.ID, got.Title, note.Title)
+		}
+
+		if _, _, err := s.List(ctx, ListOptions{OwnerID: owner, Limit: 10}); err != nil {
+			return err
+		}
+
+		got.Content = "edited"
+		if _, err := s.Update(ctx, got); err != nil {
+			return fmt.Errorf("update %d: %w", created.ID, err)
+		}
+
+		if i%2 == 1 {
+			if err := s.Delete(ctx, owner, created.ID); err != nil {
+				return fmt.Errorf("delete %d: %w", created.ID, err)
+			}
+			if _, err := s.Get(ctx, owner, created.ID); !errors.Is(err, ErrNotFound) {
+				return fmt.Errorf("get %d after delete: %v, want ErrNotFound", created.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// TestMemoryStoreDedupeConcurrent creates the same note from many goroutines
+// at once and checks that only one of them succeeds
+func TestMemoryStoreDedupeConcurrent(t *testing.T) {
+	const workers = 16
+
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := models.NewNote("same", "content")
+			n.OwnerID = "alice"
+			_, _, err := s.CreateWith(ctx, n, CreateOptions{Dedupe: true})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		var dup *DuplicateError
+		switch {
+		case err == nil:
+			created++
+		case !errors.As(err, &dup):
+			t.Errorf("CreateWith: %v, want nil or a DuplicateError", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d notes created, want 1", created)
+	}
+}
+
+func TestMemoryStoreHidesOtherOwners(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	n := models.NewNote("private", "")
+	n.OwnerID = "alice"
+	created, err := s.Create(ctx, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Get(ctx, "bob", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get by another owner: %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "bob", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete by another owner: %v, want ErrNotFound", err)
+	}
+	created.OwnerID = "bob"
+	if _, err := s.Update(ctx, created); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update by another owner: %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migrations are SQL files named <version>_<name>.sql, applied in version
+// order. Once released, a migration must never change; add a new one instead.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations reads the embedded migrations, ordered by version
+func loadMigrations() ([]migration, error) {
+	files, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	for _, file := range files {
+		base := strings.TrimSuffix(file.Name(), ".sql")
+		num, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(num)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version", file.Name())
+		}
+
+		body, err := migrationFiles.ReadFile(path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("migration version %d is used twice", migrations[i].version)
+		}
+	}
+	return migrations, nil
+}
+
+// migrate applies every migration not yet recorded in schema_migrations.
+// Each one runs in its own transaction together with its record, so a
+// failed migration leaves the database at the previous version.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT     NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	ran := 0
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+		}
+		log.Printf("applied migration %04d_%s", m.version, m.name)
+		ran++
+	}
+
+	if ran == 0 && len(migrations) > 0 {
+		log.Printf("database schema up to date at version %d", migrations[len(migrations)-1].version)
+	}
+	return nil
+}
+
+// appliedMigrations returns the versions recorded in schema_migrations
+func appliedMigrations(db *sql.DB) (map[int]bool, error) {
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Databases created before migrations existed may have a notes table
+	// older than the one 0001 describes
+	if m.version == 1 {
+		if err := upgradeLegacyNotes(tx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, time.Now().UTC(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// legacyNoteColumns are the notes columns that releases before migrations
+// added over time, with the definitions that add them to an older table.
+// Existing rows take the defaults: no owner, no folder, not pinned, not
+// archived and not deleted.
+var legacyNoteColumns = []struct{ name, def string }{
+	{"owner_id", `TEXT NOT NULL DEFAULT ''`},
+	{"folder_id", `INTEGER REFERENCES folders(id) ON DELETE SET NULL`},
+	{"pinned", `BOOLEAN NOT NULL DEFAULT FALSE`},
+	{"archived", `BOOLEAN NOT NULL DEFAULT FALSE`},
+	{"deleted_at", `DATETIME`},
+}
+
+// upgradeLegacyNotes adds whichever of legacyNoteColumns an existing notes
+// table lacks. It does nothing when there is no notes table yet.
+func upgradeLegacyNotes(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info('notes')`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil || len(columns) == 0 {
+		return err
+	}
+
+	for _, c := range legacyNoteColumns {
+		if columns[c.name] {
+			continue
+		}
+		if _, err := tx.Exec(`ALTER TABLE notes ADD COLUMN ` + c.name + ` ` + c.def); err != nil {
+			return fmt.Errorf("add notes.%s: %w", c.name, err)
+		}
+	}
+	return nil
+}
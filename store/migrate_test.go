@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestMigrateLegacyDatabase opens a database written by the first SQLite
+// release, before notes had owners, folders, pins, archiving or soft
+// deletes, and checks that its notes survive the upgrade
+func TestMigrateLegacyDatabase(t *testing.T) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	path := filepath.Join(t.TempDir(), "notes.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, stmt := range []string{
+		`CREATE TABLE notes (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			title      TEXT     NOT NULL,
+			content    TEXT     NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		)`,
+		`INSERT INTO notes (title, content, created_at, updated_at) VALUES ('old', 'kept', ?, ?)`,
+	} {
+		if _, err := db.Exec(stmt, now, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	n, err := s.Get(ctx, "", 1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if n.Title != "old" || n.Content != "kept" || n.Pinned || n.Archived || n.FolderID != nil {
+		t.Errorf("upgraded note = %+v", n)
+	}
+
+	count, err := s.Count(ctx)
+	if err != nil || count != 1 {
+		t.Errorf("Count = %d, %v, want 1", count, err)
+	}
+}
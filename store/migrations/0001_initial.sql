@@ -0,0 +1,55 @@
+-- Tables as they were before versioned migrations. IF NOT EXISTS lets
+-- databases created by earlier releases adopt this as their first version;
+-- the notes columns such a database lacks are added by the runner first
+-- (see upgradeLegacyNotes in migrate.go).
+
+CREATE TABLE IF NOT EXISTS folders (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner_id   TEXT     NOT NULL,
+	name       TEXT     NOT NULL,
+	parent_id  INTEGER  REFERENCES folders(id) ON DELETE SET NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS folders_owner_id ON folders (owner_id);
+
+CREATE TABLE IF NOT EXISTS notes (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner_id   TEXT     NOT NULL,
+	title      TEXT     NOT NULL,
+	content    TEXT     NOT NULL,
+	folder_id  INTEGER  REFERENCES folders(id) ON DELETE SET NULL,
+	pinned     BOOLEAN  NOT NULL DEFAULT FALSE,
+	archived   BOOLEAN  NOT NULL DEFAULT FALSE,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	deleted_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS notes_owner_id ON notes (owner_id);
+CREATE INDEX IF NOT EXISTS notes_folder_id ON notes (folder_id);
+
+CREATE TABLE IF NOT EXISTS note_tags (
+	note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+	tag     TEXT    NOT NULL,
+	PRIMARY KEY (note_id, tag)
+);
+
+CREATE TABLE IF NOT EXISTS note_versions (
+	note_id    INTEGER  NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+	version    INTEGER  NOT NULL,
+	title      TEXT     NOT NULL,
+	content    TEXT     NOT NULL,
+	tags       TEXT     NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (note_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	owner_id   TEXT    NOT NULL,
+	key        TEXT    NOT NULL,
+	note       TEXT    NOT NULL,
+	expires_at INTEGER NOT NULL,
+	PRIMARY KEY (owner_id, key)
+);
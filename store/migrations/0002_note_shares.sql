@@ -0,0 +1,8 @@
+-- Read-only share links. Only a hash of each token is kept, and a note has
+-- at most one link at a time. Expiry times are Unix seconds.
+
+CREATE TABLE note_shares (
+	note_id    INTEGER  PRIMARY KEY REFERENCES notes(id) ON DELETE CASCADE,
+	token_hash TEXT     NOT NULL UNIQUE,
+	expires_at INTEGER
+);
@@ -0,0 +1,14 @@
+-- Metadata of files uploaded to notes. The files are kept on disk under
+-- storage_key.
+
+CREATE TABLE attachments (
+	id           INTEGER  PRIMARY KEY,
+	note_id      INTEGER  NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+	filename     TEXT     NOT NULL,
+	content_type TEXT     NOT NULL,
+	size         INTEGER  NOT NULL,
+	storage_key  TEXT     NOT NULL,
+	created_at   DATETIME NOT NULL
+);
+
+CREATE INDEX idx_attachments_note_id ON attachments(note_id);
@@ -0,0 +1,6 @@
+-- Reminders: when a note falls due, and whether its owner has dealt with it
+
+ALTER TABLE notes ADD COLUMN remind_at DATETIME;
+ALTER TABLE notes ADD COLUMN reminder_acknowledged BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_notes_remind_at ON notes(remind_at) WHERE remind_at IS NOT NULL;
@@ -0,0 +1,939 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriver is the sqlite3 driver with a fold() function that lower-cases
+// text as the memory store does. SQLite's NOCASE and LIKE only fold ASCII
+// letters, so tag filters, searches and title sorts use fold() instead.
+const sqliteDriver = "sqlite3_notes"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("fold", strings.ToLower, true)
+		},
+	})
+}
+
+// noteColumns selects a note row in the order expected by scanNote. Tags are
+// aggregated into a JSON array, in the order they were saved.
+const noteColumns = `id, owner_id, title, content, folder_id, pinned, archived, created_at, updated_at, deleted_at,
+	remind_at, reminder_acknowledged,
+	(SELECT json_group_array(tag) FROM (SELECT tag FROM note_tags WHERE note_id = notes.id ORDER BY rowid))`
+
+// attachmentColumns selects an attachment row in the order expected by
+// scanAttachment
+const attachmentColumns = `id, note_id, filename, content_type, size, storage_key, created_at`
+
+// folderColumns selects a folder row in the order expected by scanFolder
+const folderColumns = `id, owner_id, name, parent_id, created_at, updated_at`
+
+// SQLiteStore persists notes in a SQLite database file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens the database at path and applies any pending
+// migrations
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open(sqliteDriver, path+"?_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only allows a single writer at a time
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Create(ctx context.Context, n models.Note) (models.Note, error) {
+	created, err := s.CreateMany(ctx, []models.Note{n})
+	if err != nil {
+		return models.Note{}, err
+	}
+	return created[0], nil
+}
+
+// CreateWith runs its checks and the insert in one transaction. A key's
+// note is kept as JSON, so a replay returns it unchanged even if the note
+// was edited since. Expiry times are stored as Unix seconds.
+func (s *SQLiteStore) CreateWith(ctx context.Context, n models.Note, opts CreateOptions) (models.Note, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Note{}, false, err
+	}
+	defer tx.Rollback()
+
+	if opts.Key != "" {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, time.Now().Unix()); err != nil {
+			return models.Note{}, false, err
+		}
+
+		var saved string
+		err = tx.QueryRowContext(ctx,
+			`SELECT note FROM idempotency_keys WHERE owner_id = ? AND key = ?`, n.OwnerID, opts.Key,
+		).Scan(&saved)
+		if err == nil {
+			var original models.Note
+			if err := json.Unmarshal([]byte(saved), &original); err != nil {
+				return models.Note{}, false, err
+			}
+			return original, false, tx.Commit()
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return models.Note{}, false, err
+		}
+	}
+
+	if opts.Dedupe {
+		id, found, err := findDuplicate(ctx, tx, n)
+		if err != nil {
+			return models.Note{}, false, err
+		}
+		if found {
+			return models.Note{}, false, &DuplicateError{ID: id}
+		}
+	}
+
+	n.ID = 0
+	n, err = insertNote(ctx, tx, n)
+	if err != nil {
+		return models.Note{}, false, err
+	}
+
+	if opts.Key != "" {
+		b, err := json.Marshal(n)
+		if err != nil {
+			return models.Note{}, false, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO idempotency_keys (owner_id, key, note, expires_at) VALUES (?, ?, ?, ?)`,
+			n.OwnerID, opts.Key, string(b), opts.ExpiresAt.Unix(),
+		); err != nil {
+			return models.Note{}, false, err
+		}
+	}
+	return n, true, tx.Commit()
+}
+
+func (s *SQLiteStore) CreateMany(ctx context.Context, notes []models.Note) ([]models.Note, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created := make([]models.Note, 0, len(notes))
+	for _, n := range notes {
+		n.ID = 0
+		n, err := insertNote(ctx, tx, n)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, n)
+	}
+	return created, tx.Commit()
+}
+
+func (s *SQLiteStore) Import(ctx context.Context, notes []models.Note, preserveIDs bool) ([]models.Note, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	imported, skipped := make([]models.Note, 0, len(notes)), 0
+	for _, n := range notes {
+		if !preserveIDs {
+			n.ID = 0
+		} else if n.ID > 0 {
+			var taken bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM notes WHERE id = ?)`, n.ID).Scan(&taken); err != nil {
+				return nil, 0, err
+			}
+			if taken {
+				skipped++
+				continue
+			}
+		}
+
+		// Sorting compares the stored text, so every time must be in UTC
+		n.CreatedAt, n.UpdatedAt = n.CreatedAt.UTC(), n.UpdatedAt.UTC()
+		n, err := insertNote(ctx, tx, n)
+		if err != nil {
+			return nil, 0, err
+		}
+		imported = append(imported, n)
+	}
+	return imported, skipped, tx.Commit()
+}
+
+func (s *SQLiteStore) Get(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+noteColumns+` FROM notes WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`, id, ownerID)
+
+	n, err := scanNote(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Note{}, ErrNotFound
+	}
+	return n, err
+}
+
+func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]models.Note, int, error) {
+	where, args := listFilter(opts)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notes`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// A negative LIMIT means no limit in SQLite
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+noteColumns+` FROM notes`+where+orderBy(opts.Sort)+` LIMIT ? OFFSET ?`,
+		append(args, limit, opts.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []models.Note{}
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, n)
+	}
+	return list, total, rows.Err()
+}
+
+func (s *SQLiteStore) Count(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notes WHERE deleted_at IS NULL`).Scan(&count)
+	return count, err
+}
+
+// Update replaces the stored note with n, keeping its creation time
+func (s *SQLiteStore) Update(ctx context.Context, n models.Note) (models.Note, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Note{}, err
+	}
+	defer tx.Rollback()
+
+	old, err := scanNote(tx.QueryRowContext(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`,
+		n.ID, n.OwnerID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Note{}, ErrNotFound
+	} else if err != nil {
+		return models.Note{}, err
+	}
+
+	if err := saveVersion(ctx, tx, old); err != nil {
+		return models.Note{}, err
+	}
+
+	acknowledged := old.ReminderAcknowledged && sameTime(old.RemindAt, n.RemindAt)
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE notes SET title = ?, content = ?, folder_id = ?, remind_at = ?, reminder_acknowledged = ?, updated_at = ? WHERE id = ?`,
+		n.Title, n.Content, n.FolderID, n.RemindAt, acknowledged, time.Now().UTC(), n.ID,
+	); err != nil {
+		return models.Note{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM note_tags WHERE note_id = ?`, n.ID); err != nil {
+		return models.Note{}, err
+	}
+	if err := saveTags(ctx, tx, n.ID, n.Tags); err != nil {
+		return models.Note{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Note{}, err
+	}
+	return s.Get(ctx, n.OwnerID, n.ID)
+}
+
+func (s *SQLiteStore) Delete(ctx context.Context, ownerID string, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE notes SET deleted_at = ? WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`,
+		time.Now().UTC(), id, ownerID,
+	)
+	return checkAffected(res, err)
+}
+
+func (s *SQLiteStore) DeleteMany(ctx context.Context, ownerID string, ids []int64) ([]int64, []int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	deleted, notFound := []int64{}, []int64{}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		res, err := tx.ExecContext(ctx,
+			`UPDATE notes SET deleted_at = ? WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`,
+			now, id, ownerID,
+		)
+		switch err := checkAffected(res, err); {
+		case errors.Is(err, ErrNotFound):
+			notFound = append(notFound, id)
+		case err != nil:
+			return nil, nil, err
+		default:
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, notFound, tx.Commit()
+}
+
+func (s *SQLiteStore) SetPinned(ctx context.Context, ownerID string, id int64, pinned bool) (models.Note, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE notes SET pinned = ? WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`,
+		pinned, id, ownerID,
+	)
+	if err := checkAffected(res, err); err != nil {
+		return models.Note{}, err
+	}
+	return s.Get(ctx, ownerID, id)
+}
+
+func (s *SQLiteStore) SetArchived(ctx context.Context, ownerID string, id int64, archived bool) (models.Note, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE notes SET archived = ? WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`,
+		archived, id, ownerID,
+	)
+	if err := checkAffected(res, err); err != nil {
+		return models.Note{}, err
+	}
+	return s.Get(ctx, ownerID, id)
+}
+
+func (s *SQLiteStore) Restore(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE notes SET deleted_at = NULL WHERE id = ? AND owner_id = ? AND deleted_at IS NOT NULL`,
+		id, ownerID,
+	)
+	if err := checkAffected(res, err); err != nil {
+		return models.Note{}, err
+	}
+	return s.Get(ctx, ownerID, id)
+}
+
+func (s *SQLiteStore) Purge(ctx context.Context, ownerID string, id int64) error {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM notes WHERE id = ? AND owner_id = ? AND deleted_at IS NOT NULL`,
+		id, ownerID,
+	)
+	return checkAffected(res, err)
+}
+
+func (s *SQLiteStore) Versions(ctx context.Context, ownerID string, id int64) ([]models.NoteVersion, error) {
+	if _, err := s.Get(ctx, ownerID, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT version, title, content, tags, updated_at FROM note_versions WHERE note_id = ? ORDER BY version`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.NoteVersion{}
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (s *SQLiteStore) Version(ctx context.Context, ownerID string, id int64, version int) (models.NoteVersion, error) {
+	if _, err := s.Get(ctx, ownerID, id); err != nil {
+		return models.NoteVersion{}, err
+	}
+
+	v, err := scanVersion(s.db.QueryRowContext(ctx,
+		`SELECT version, title, content, tags, updated_at FROM note_versions WHERE note_id = ? AND version = ?`,
+		id, version,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.NoteVersion{}, ErrVersionNotFound
+	}
+	return v, err
+}
+
+func (s *SQLiteStore) Reminders(ctx context.Context, opts ReminderOptions) ([]models.Note, error) {
+	conds := []string{`remind_at IS NOT NULL`, `NOT reminder_acknowledged`, `deleted_at IS NULL`, `remind_at <= ?`}
+	args := []any{opts.Before.UTC()}
+
+	if opts.OwnerID != "" {
+		conds = append(conds, `owner_id = ?`)
+		args = append(args, opts.OwnerID)
+	}
+	if opts.After != nil {
+		conds = append(conds, `remind_at > ?`)
+		args = append(args, opts.After.UTC())
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+noteColumns+` FROM notes WHERE `+strings.Join(conds, " AND ")+` ORDER BY remind_at, id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []models.Note{}
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, n)
+	}
+	return due, rows.Err()
+}
+
+func (s *SQLiteStore) AcknowledgeReminder(ctx context.Context, ownerID string, id int64) (models.Note, error) {
+	n, err := s.Get(ctx, ownerID, id)
+	if err != nil {
+		return models.Note{}, err
+	}
+	if n.RemindAt == nil {
+		return models.Note{}, ErrNoReminder
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE notes SET reminder_acknowledged = 1 WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`,
+		id, ownerID,
+	)
+	if err := checkAffected(res, err); err != nil {
+		return models.Note{}, err
+	}
+
+	n.ReminderAcknowledged = true
+	return n, nil
+}
+
+func (s *SQLiteStore) ShareNote(ctx context.Context, ownerID string, id int64, tokenHash string, expiresAt *time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT 1 FROM notes WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`, id, ownerID,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var expires *int64
+	if expiresAt != nil {
+		unix := expiresAt.Unix()
+		expires = &unix
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO note_shares (note_id, token_hash, expires_at) VALUES (?, ?, ?)`,
+		id, tokenHash, expires,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) UnshareNote(ctx context.Context, ownerID string, id int64) error {
+	if _, err := s.Get(ctx, ownerID, id); err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM note_shares WHERE note_id = ?`, id)
+	if err := checkAffected(res, err); errors.Is(err, ErrNotFound) {
+		return ErrShareNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SharedNote(ctx context.Context, tokenHash string) (models.Note, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+noteColumns+` FROM notes
+		JOIN note_shares ON note_shares.note_id = notes.id
+		WHERE note_shares.token_hash = ? AND notes.deleted_at IS NULL
+		AND (note_shares.expires_at IS NULL OR note_shares.expires_at > ?)`,
+		tokenHash, time.Now().Unix(),
+	)
+
+	n, err := scanNote(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Note{}, ErrShareNotFound
+	}
+	return n, err
+}
+
+func (s *SQLiteStore) CreateAttachment(ctx context.Context, ownerID string, a models.Attachment) (models.Attachment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT 1 FROM notes WHERE id = ? AND owner_id = ? AND deleted_at IS NULL`, a.NoteID, ownerID,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Attachment{}, ErrNotFound
+	}
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO attachments (note_id, filename, content_type, size, storage_key, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		a.NoteID, a.Filename, a.ContentType, a.Size, a.StorageKey, a.CreatedAt,
+	)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	a.ID, err = res.LastInsertId()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	return a, tx.Commit()
+}
+
+func (s *SQLiteStore) Attachments(ctx context.Context, ownerID string, noteID int64) ([]models.Attachment, error) {
+	if _, err := s.Get(ctx, ownerID, noteID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+attachmentColumns+` FROM attachments WHERE note_id = ? ORDER BY id`, noteID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (s *SQLiteStore) Attachment(ctx context.Context, ownerID string, noteID, id int64) (models.Attachment, error) {
+	if _, err := s.Get(ctx, ownerID, noteID); err != nil {
+		return models.Attachment{}, err
+	}
+
+	a, err := scanAttachment(s.db.QueryRowContext(ctx,
+		`SELECT `+attachmentColumns+` FROM attachments WHERE id = ? AND note_id = ?`, id, noteID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Attachment{}, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+func (s *SQLiteStore) CreateFolder(ctx context.Context, f models.Folder) (models.Folder, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Folder{}, err
+	}
+	defer tx.Rollback()
+
+	if f.ParentID != nil {
+		if _, err := getFolder(ctx, tx, f.OwnerID, *f.ParentID); errors.Is(err, ErrFolderNotFound) {
+			return models.Folder{}, ErrParentNotFound
+		} else if err != nil {
+			return models.Folder{}, err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO folders (owner_id, name, parent_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		f.OwnerID, f.Name, f.ParentID, f.CreatedAt, f.UpdatedAt,
+	)
+	if err != nil {
+		return models.Folder{}, err
+	}
+
+	f.ID, err = res.LastInsertId()
+	if err != nil {
+		return models.Folder{}, err
+	}
+	return f, tx.Commit()
+}
+
+func (s *SQLiteStore) GetFolder(ctx context.Context, ownerID string, id int64) (models.Folder, error) {
+	return getFolder(ctx, s.db, ownerID, id)
+}
+
+func (s *SQLiteStore) ListFolders(ctx context.Context, ownerID string) ([]models.Folder, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+folderColumns+` FROM folders WHERE owner_id = ? ORDER BY fold(name), id`, ownerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.Folder{}
+	for rows.Next() {
+		f, err := scanFolder(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+// UpdateFolder replaces the name and parent of the stored folder, keeping
+// its creation time
+func (s *SQLiteStore) UpdateFolder(ctx context.Context, f models.Folder) (models.Folder, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Folder{}, err
+	}
+	defer tx.Rollback()
+
+	old, err := getFolder(ctx, tx, f.OwnerID, f.ID)
+	if err != nil {
+		return models.Folder{}, err
+	}
+
+	// Walk up from the new parent; reaching f means it would be its own
+	// ancestor
+	for parent := f.ParentID; parent != nil; {
+		if *parent == f.ID {
+			return models.Folder{}, ErrFolderCycle
+		}
+		p, err := getFolder(ctx, tx, f.OwnerID, *parent)
+		if errors.Is(err, ErrFolderNotFound) {
+			return models.Folder{}, ErrParentNotFound
+		} else if err != nil {
+			return models.Folder{}, err
+		}
+		parent = p.ParentID
+	}
+
+	f.CreatedAt = old.CreatedAt
+	f.UpdatedAt = time.Now().UTC()
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE folders SET name = ?, parent_id = ?, updated_at = ? WHERE id = ?`,
+		f.Name, f.ParentID, f.UpdatedAt, f.ID,
+	); err != nil {
+		return models.Folder{}, err
+	}
+	return f, tx.Commit()
+}
+
+// DeleteFolder relies on ON DELETE SET NULL to move the contents of the
+// folder to the root
+func (s *SQLiteStore) DeleteFolder(ctx context.Context, ownerID string, id int64, mode FolderDeleteMode) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := getFolder(ctx, tx, ownerID, id); err != nil {
+		return err
+	}
+
+	if mode == FolderDeleteReject {
+		var nonEmpty bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM notes WHERE folder_id = ? AND deleted_at IS NULL)
+			OR EXISTS (SELECT 1 FROM folders WHERE parent_id = ?)`,
+			id, id,
+		).Scan(&nonEmpty); err != nil {
+			return err
+		}
+		if nonEmpty {
+			return ErrFolderNotEmpty
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM folders WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// listFilter builds the WHERE clause for opts
+func listFilter(opts ListOptions) (string, []any) {
+	conds := []string{`owner_id = ?`}
+	args := []any{opts.OwnerID}
+
+	if !opts.IncludeDeleted {
+		conds = append(conds, `deleted_at IS NULL`)
+	}
+
+	if opts.FolderID != nil {
+		conds = append(conds, `folder_id = ?`)
+		args = append(args, *opts.FolderID)
+	}
+
+	switch opts.Archived {
+	case ArchivedExclude:
+		conds = append(conds, `NOT archived`)
+	case ArchivedOnly:
+		conds = append(conds, `archived`)
+	}
+
+	for _, tag := range opts.Tags {
+		conds = append(conds, `id IN (SELECT note_id FROM note_tags WHERE fold(tag) = ?)`)
+		args = append(args, strings.ToLower(tag))
+	}
+
+	// instr() matches the text literally, with no wildcards to escape
+	if opts.Query != "" {
+		q := strings.ToLower(opts.Query)
+		conds = append(conds, `(instr(fold(title), ?) > 0 OR instr(fold(content), ?) > 0)`)
+		args = append(args, q, q)
+	}
+
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// sortColumns maps sort fields to the SQL they order by
+var sortColumns = map[string]string{
+	SortCreatedAt: "created_at",
+	SortUpdatedAt: "updated_at",
+	SortTitle:     "fold(title)",
+}
+
+func orderBy(by Sort) string {
+	dir := " ASC"
+	if by.Desc {
+		dir = " DESC"
+	}
+
+	order := " ORDER BY "
+	if by.PinnedFirst {
+		order += "pinned DESC, "
+	}
+
+	column, ok := sortColumns[by.Field]
+	if !ok {
+		return order + "id" + dir
+	}
+	return order + column + dir + ", id" + dir
+}
+
+// checkAffected turns a statement that matched no rows into ErrNotFound
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func saveTags(ctx context.Context, tx *sql.Tx, noteID int64, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO note_tags (note_id, tag) VALUES (?, ?)`, noteID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertNote inserts n, keeping n.ID if it is set
+func insertNote(ctx context.Context, tx *sql.Tx, n models.Note) (models.Note, error) {
+	var id any
+	if n.ID > 0 {
+		id = n.ID
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO notes (id, owner_id, title, content, folder_id, pinned, archived, created_at, updated_at, remind_at, reminder_acknowledged)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, n.OwnerID, n.Title, n.Content, n.FolderID, n.Pinned, n.Archived, n.CreatedAt, n.UpdatedAt, n.RemindAt, n.ReminderAcknowledged,
+	)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	n.ID, err = res.LastInsertId()
+	if err != nil {
+		return models.Note{}, err
+	}
+	return n, saveTags(ctx, tx, n.ID, n.Tags)
+}
+
+// saveVersion records n as the next version of itself
+func saveVersion(ctx context.Context, tx *sql.Tx, n models.Note) error {
+	tags, err := json.Marshal(n.Tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO note_versions (note_id, version, title, content, tags, updated_at)
+		SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ? FROM note_versions WHERE note_id = ?`,
+		n.ID, n.Title, n.Content, string(tags), n.UpdatedAt, n.ID,
+	)
+	return err
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getFolder(ctx context.Context, q querier, ownerID string, id int64) (models.Folder, error) {
+	f, err := scanFolder(q.QueryRowContext(ctx,
+		`SELECT `+folderColumns+` FROM folders WHERE id = ? AND owner_id = ?`, id, ownerID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Folder{}, ErrFolderNotFound
+	}
+	return f, err
+}
+
+// findDuplicate narrows the candidates down by title, which Note.Validate
+// trims before any note is stored, and compares their content in Go since
+// SQLite's trim() only strips spaces
+func findDuplicate(ctx context.Context, tx *sql.Tx, n models.Note) (int64, bool, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, content FROM notes WHERE owner_id = ? AND title = ? AND deleted_at IS NULL ORDER BY id`,
+		n.OwnerID, strings.TrimSpace(n.Title),
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	defer rows.Close()
+
+	content := strings.TrimSpace(n.Content)
+	for rows.Next() {
+		var id int64
+		var other string
+		if err := rows.Scan(&id, &other); err != nil {
+			return 0, false, err
+		}
+		if strings.TrimSpace(other) == content {
+			return id, true, nil
+		}
+	}
+	return 0, false, rows.Err()
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNote(row scanner) (models.Note, error) {
+	var n models.Note
+	var deletedAt, remindAt sql.NullTime
+	var tags string
+	if err := row.Scan(&n.ID, &n.OwnerID, &n.Title, &n.Content, &n.FolderID, &n.Pinned, &n.Archived, &n.CreatedAt, &n.UpdatedAt, &deletedAt,
+		&remindAt, &n.ReminderAcknowledged, &tags); err != nil {
+		return models.Note{}, err
+	}
+
+	if deletedAt.Valid {
+		n.DeletedAt = &deletedAt.Time
+	}
+	if remindAt.Valid {
+		n.RemindAt = &remindAt.Time
+	}
+
+	if err := json.Unmarshal([]byte(tags), &n.Tags); err != nil {
+		return models.Note{}, err
+	}
+	return n, nil
+}
+
+func scanAttachment(row scanner) (models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.NoteID, &a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt)
+	return a, err
+}
+
+func scanFolder(row scanner) (models.Folder, error) {
+	var f models.Folder
+	err := row.Scan(&f.ID, &f.OwnerID, &f.Name, &f.ParentID, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+func scanVersion(row scanner) (models.NoteVersion, error) {
+	var v models.NoteVersion
+	var tags string
+	if err := row.Scan(&v.Version, &v.Title, &v.Content, &tags, &v.UpdatedAt); err != nil {
+		return models.NoteVersion{}, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &v.Tags); err != nil {
+		return models.NoteVersion{}, err
+	}
+	return v, nil
+}
@@ -0,0 +1,254 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+)
+
+var (
+	// ErrNotFound is returned when no note exists with the requested id, or
+	// it belongs to another owner
+	ErrNotFound = errors.New("note not found")
+
+	// ErrVersionNotFound is returned when a note has no such version
+	ErrVersionNotFound = errors.New("version not found")
+
+	// ErrShareNotFound is returned when a share token is unknown or expired,
+	// or a note has not been shared
+	ErrShareNotFound = errors.New("share not found")
+
+	// ErrNoReminder is returned when acknowledging the reminder of a note
+	// that has none
+	ErrNoReminder = errors.New("note has no reminder")
+
+	// ErrAttachmentNotFound is returned when a note has no attachment with
+	// the given id
+	ErrAttachmentNotFound = errors.New("attachment not found")
+
+	// ErrFolderNotFound is returned when no folder exists with the
+	// requested id, or it belongs to another owner
+	ErrFolderNotFound = errors.New("folder not found")
+
+	// ErrParentNotFound is returned when the parent given for a folder
+	// does not exist
+	ErrParentNotFound = errors.New("parent folder not found")
+
+	// ErrFolderCycle is returned when a folder would become its own
+	// ancestor
+	ErrFolderCycle = errors.New("folder cannot be moved inside itself")
+
+	// ErrFolderNotEmpty is returned when deleting a folder that still holds
+	// notes or subfolders with FolderDeleteReject
+	ErrFolderNotEmpty = errors.New("folder is not empty")
+)
+
+// DuplicateError is returned when CreateWith is asked to dedupe and the
+// note repeats an existing one
+type DuplicateError struct {
+	// ID is the existing note
+	ID int64
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("note %d has the same title and content", e.ID)
+}
+
+// FolderDeleteMode decides what DeleteFolder does with the contents of a
+// folder
+type FolderDeleteMode string
+
+const (
+	// FolderDeleteReject refuses to delete folders holding notes or
+	// subfolders
+	FolderDeleteReject FolderDeleteMode = "reject"
+	// FolderDeleteMove moves the notes and subfolders of the folder to the
+	// root
+	FolderDeleteMove FolderDeleteMode = "move"
+)
+
+// Fields notes can be sorted by
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortTitle     = "title"
+)
+
+// Sort orders the notes returned by List. Ties are broken by id.
+type Sort struct {
+	Field string
+	Desc  bool
+
+	// PinnedFirst puts pinned notes before all others, whatever Desc is
+	PinnedFirst bool
+}
+
+// ParseSort parses a sort parameter such as "title" or "-created_at", where
+// a leading "-" means descending
+func ParseSort(value string) (Sort, error) {
+	field, desc := strings.CutPrefix(value, "-")
+	switch field {
+	case SortCreatedAt, SortUpdatedAt, SortTitle:
+		return Sort{Field: field, Desc: desc}, nil
+	}
+	return Sort{}, fmt.Errorf("unknown sort field %q", field)
+}
+
+// CreateOptions adjusts how CreateWith creates a note
+type CreateOptions struct {
+	// Key, when set, makes creation idempotent: until ExpiresAt, a later
+	// call by the same owner with the same key returns the note created by
+	// the first call, as it was then, instead of creating another
+	Key       string
+	ExpiresAt time.Time
+
+	// Dedupe refuses to create a note whose title and content, once
+	// surrounding whitespace is trimmed, match one of its owner's notes
+	// other than soft-deleted ones. A DuplicateError names the oldest such
+	// note. A replayed Key is answered before this check.
+	Dedupe bool
+}
+
+// ArchivedFilter selects notes by whether they are archived
+type ArchivedFilter int
+
+const (
+	// ArchivedAny matches notes whether they are archived or not
+	ArchivedAny ArchivedFilter = iota
+	// ArchivedExclude only matches notes that are not archived
+	ArchivedExclude
+	// ArchivedOnly only matches archived notes
+	ArchivedOnly
+)
+
+// ListOptions narrows down the notes returned by List
+type ListOptions struct {
+	// OwnerID only matches notes belonging to this user
+	OwnerID string
+
+	// Limit caps the page size; zero returns every match
+	Limit  int
+	Offset int
+	Sort   Sort
+
+	// Tags only matches notes carrying every one of these tags,
+	// compared case-insensitively
+	Tags []string
+
+	// Query only matches notes whose title or content contains it,
+	// compared case-insensitively
+	Query string
+
+	// IncludeDeleted also returns soft-deleted notes
+	IncludeDeleted bool
+
+	// Archived chooses between archived and other notes
+	Archived ArchivedFilter
+
+	// FolderID only matches notes directly inside this folder
+	FolderID *int64
+}
+
+// ReminderOptions selects the reminders returned by Reminders
+type ReminderOptions struct {
+	// OwnerID only matches notes belonging to this user; empty matches
+	// the notes of every user
+	OwnerID string
+
+	// After, when set, only matches reminders due after it
+	After *time.Time
+
+	// Before only matches reminders due at or before it
+	Before time.Time
+}
+
+// Store is the persistence layer used by the controllers. Every method but
+// Close gives up once ctx is done, returning its error. Every lookup but
+// Count, SharedNote and Reminders across owners is scoped to an owner; notes
+// of other owners behave as if they do not exist.
+// Soft-deleted notes are likewise hidden from everything but List with
+// IncludeDeleted, Restore and Purge.
+type Store interface {
+	Create(ctx context.Context, n models.Note) (models.Note, error)
+	// CreateWith creates n as adjusted by opts. The checks and the creation
+	// happen atomically. created is false when a Key was replayed.
+	CreateWith(ctx context.Context, n models.Note, opts CreateOptions) (note models.Note, created bool, err error)
+	// CreateMany creates all notes or, on error, none of them
+	CreateMany(ctx context.Context, notes []models.Note) ([]models.Note, error)
+	// Import creates notes exported earlier, keeping their timestamps. With
+	// preserveIDs their original ids are kept, and notes whose id is already
+	// taken, by any owner, are skipped. Either every note is imported or
+	// skipped, or none. The notes imported are returned as stored.
+	Import(ctx context.Context, notes []models.Note, preserveIDs bool) (imported []models.Note, skipped int, err error)
+	Get(ctx context.Context, ownerID string, id int64) (models.Note, error)
+	// List returns one page of notes along with the total number of matches
+	List(ctx context.Context, opts ListOptions) ([]models.Note, int, error)
+	// Count returns the number of notes across all owners, not counting
+	// soft-deleted ones
+	Count(ctx context.Context) (int, error)
+	// Update replaces the note with the id and owner of n, recording its
+	// previous state as a new version
+	Update(ctx context.Context, n models.Note) (models.Note, error)
+	// SetPinned pins or unpins a note. It is not an edit, so no version is
+	// recorded and the update time is left alone.
+	SetPinned(ctx context.Context, ownerID string, id int64, pinned bool) (models.Note, error)
+	// SetArchived archives or unarchives a note. Like SetPinned it is not
+	// an edit.
+	SetArchived(ctx context.Context, ownerID string, id int64, archived bool) (models.Note, error)
+	// Delete soft-deletes a note so it can still be restored
+	Delete(ctx context.Context, ownerID string, id int64) error
+	// DeleteMany soft-deletes several notes at once. It returns the ids it
+	// deleted and those matching no note, both in the order given, and
+	// deletes nothing on error. An id given twice counts once.
+	DeleteMany(ctx context.Context, ownerID string, ids []int64) (deleted, notFound []int64, err error)
+	// Restore undoes the soft delete of a note
+	Restore(ctx context.Context, ownerID string, id int64) (models.Note, error)
+	// Purge permanently removes a soft-deleted note
+	Purge(ctx context.Context, ownerID string, id int64) error
+
+	// Versions lists the previous states of a note, oldest first
+	Versions(ctx context.Context, ownerID string, id int64) ([]models.NoteVersion, error)
+	Version(ctx context.Context, ownerID string, id int64, version int) (models.NoteVersion, error)
+
+	// Reminders lists the unacknowledged reminders selected by opts, due
+	// soonest first. Notes without a reminder and deleted notes are left out.
+	Reminders(ctx context.Context, opts ReminderOptions) ([]models.Note, error)
+	// AcknowledgeReminder marks the reminder of a note as dealt with
+	AcknowledgeReminder(ctx context.Context, ownerID string, id int64) (models.Note, error)
+
+	// ShareNote gives a note a share link, replacing any earlier one. Only
+	// the hash of the token is stored; expiresAt may be nil.
+	ShareNote(ctx context.Context, ownerID string, id int64, tokenHash string, expiresAt *time.Time) error
+	// UnshareNote revokes the share link of a note
+	UnshareNote(ctx context.Context, ownerID string, id int64) error
+	// SharedNote looks up a note by the hash of its share token, for anyone
+	SharedNote(ctx context.Context, tokenHash string) (models.Note, error)
+
+	// CreateAttachment records a file uploaded to one of the owner's notes
+	// and returns it with its id set
+	CreateAttachment(ctx context.Context, ownerID string, a models.Attachment) (models.Attachment, error)
+	// Attachments lists the attachments of a note, oldest first
+	Attachments(ctx context.Context, ownerID string, noteID int64) ([]models.Attachment, error)
+	// Attachment returns one attachment of a note
+	Attachment(ctx context.Context, ownerID string, noteID, id int64) (models.Attachment, error)
+
+	// CreateFolder creates f. Its parent, if set, must be a folder of the
+	// same owner.
+	CreateFolder(ctx context.Context, f models.Folder) (models.Folder, error)
+	GetFolder(ctx context.Context, ownerID string, id int64) (models.Folder, error)
+	// ListFolders returns every folder of an owner, ordered by name
+	ListFolders(ctx context.Context, ownerID string) ([]models.Folder, error)
+	// UpdateFolder renames or moves the folder with the id and owner of f
+	UpdateFolder(ctx context.Context, f models.Folder) (models.Folder, error)
+	// DeleteFolder removes a folder. Soft-deleted notes do not count as
+	// contents and always move to the root.
+	DeleteFolder(ctx context.Context, ownerID string, id int64, mode FolderDeleteMode) error
+
+	// Ping reports whether the store is reachable
+	Ping(ctx context.Context) error
+	Close() error
+}
@@ -0,0 +1,235 @@
+// Package webhooks delivers note changes to HTTP endpoints configured by the
+// operator
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aminofabian/notes/models"
+	"github.com/aminofabian/notes/store"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, keyed with
+// the shared secret, as "sha256=<hex>"
+const SignatureHeader = "X-Notes-Signature"
+
+const (
+	// queueSize is how many deliveries may wait for a worker before new
+	// ones are dropped
+	queueSize = 1000
+	workers   = 4
+
+	// maxAttempts includes the first try. Retries wait firstBackoff, then
+	// twice as long each time.
+	maxAttempts  = 4
+	firstBackoff = time.Second
+
+	requestTimeout = 10 * time.Second
+)
+
+// Payload is the JSON body of a webhook request. Note is left out for
+// deletions.
+type Payload struct {
+	ID        string          `json:"id"`
+	Type      store.EventType `json:"type"`
+	NoteID    int64           `json:"note_id"`
+	OwnerID   string          `json:"owner_id"`
+	Note      *models.Note    `json:"note,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+type delivery struct {
+	url     string
+	id      string
+	event   store.EventType
+	payload []byte
+}
+
+// Dispatcher posts every event it is given to each of its URLs. Deliveries
+// happen in the background and in no particular order, and failed ones are
+// retried with exponential backoff.
+type Dispatcher struct {
+	urls   []string
+	secret []byte
+	client *http.Client
+
+	queue  chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New returns a Dispatcher with its workers running. Requests are signed
+// with secret.
+func New(urls []string, secret []byte) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		urls:   urls,
+		secret: secret,
+		client: &http.Client{Timeout: requestTimeout},
+		queue:  make(chan delivery, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	d.wg.Add(workers)
+	for range workers {
+		go d.work()
+	}
+	return d
+}
+
+// Enqueue schedules ev for delivery to every URL without blocking. It can
+// be passed to store.Hub.Observe.
+func (d *Dispatcher) Enqueue(ev store.Event) {
+	id, err := newDeliveryID()
+	if err != nil {
+		log.Printf("webhook: %v", err)
+		return
+	}
+	payload, err := json.Marshal(Payload{
+		ID:        id,
+		Type:      ev.Type,
+		NoteID:    ev.NoteID,
+		OwnerID:   ev.OwnerID,
+		Note:      ev.Note,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("webhook: encoding event: %v", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+	for _, url := range d.urls {
+		select {
+		case d.queue <- delivery{url: url, id: id, event: ev.Type, payload: payload}:
+		default:
+			log.Printf("webhook %s: queue full, dropping delivery %s", url, id)
+		}
+	}
+}
+
+// Close stops accepting events and waits for queued deliveries to finish.
+// When ctx is done first, the remaining deliveries are abandoned.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for del := range d.queue {
+		d.deliver(del)
+	}
+}
+
+// deliver posts one payload, retrying network errors, rate limiting and
+// server errors. Other responses are final.
+func (d *Dispatcher) deliver(del delivery) {
+	backoff := firstBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(del)
+		if err == nil {
+			return
+		}
+		if !retry || attempt == maxAttempts {
+			log.Printf("webhook %s: delivery %s failed after %d attempts: %v", del.url, del.id, attempt, err)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-d.ctx.Done():
+			log.Printf("webhook %s: delivery %s abandoned at shutdown", del.url, del.id)
+			return
+		}
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying
+func (d *Dispatcher) post(del delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(d.ctx, "POST", del.url, bytes.NewReader(del.payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notes-webhooks")
+	req.Header.Set("X-Notes-Event", string(del.event))
+	req.Header.Set("X-Notes-Delivery", del.id)
+	req.Header.Set(SignatureHeader, Sign(d.secret, del.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return false, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// Sign returns the value of SignatureHeader for body. Receivers compute it
+// the same way and compare the two with hmac.Equal.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID returns a random id that identifies a delivery across its
+// attempts, so receivers can ignore duplicates
+func newDeliveryID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating delivery id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}